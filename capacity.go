@@ -0,0 +1,101 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// diegoCellJob is the job of the Diego cells running application containers
+const diegoCellJob = "diego_cell"
+
+// Capacity multiplies the ram, cpu and disk resources of every job by its
+// instance count and sums them per product and per availability zone. The
+// Diego cell capacity is the memory and ephemeral disk of the cells, which
+// they offer to application containers. Compilation and errand vms are left
+// out, as they only run during a deployment or an errand.
+func (is *InstallationSettings) Capacity() *CapacityReport {
+	report := &CapacityReport{
+		Total:      CapacityUsage{Name: "total"},
+		DiegoCells: DiegoCellCapacity{AvailabilityZones: make(map[string]int)},
+	}
+	zones := make(map[string]int)
+	for _, az := range is.Infrastructure.AvailabilityZones {
+		zones[az.Name] = len(report.AvailabilityZones)
+		report.AvailabilityZones = append(report.AvailabilityZones, CapacityUsage{Name: az.Name})
+	}
+
+	for i := range is.Products {
+		product := &is.Products[i]
+		usage := CapacityUsage{Name: productName(product)}
+		for j := range product.Jobs {
+			job := &product.Jobs[j]
+			if jobName(job) == compilationJob || errandJobs[jobName(job)] {
+				continue
+			}
+			for _, partition := range job.Partition {
+				if partition.InstanceCount == 0 {
+					continue
+				}
+				az := partition.AvailabilityZoneReference
+				if zone := is.Infrastructure.findAvailabilityZone(az); zone != nil {
+					az = zone.Name
+				}
+				if _, ok := zones[az]; !ok {
+					zones[az] = len(report.AvailabilityZones)
+					report.AvailabilityZones = append(report.AvailabilityZones, CapacityUsage{Name: az})
+				}
+				usage.add(job, partition.InstanceCount)
+				report.AvailabilityZones[zones[az]].add(job, partition.InstanceCount)
+				report.Total.add(job, partition.InstanceCount)
+
+				if jobName(job) == diegoCellJob {
+					report.DiegoCells.Cells += partition.InstanceCount
+					report.DiegoCells.MemoryMB += partition.InstanceCount * job.resource("ram")
+					report.DiegoCells.DiskMB += partition.InstanceCount * job.resource("ephemeral_disk")
+					report.DiegoCells.AvailabilityZones[az] += partition.InstanceCount
+				}
+			}
+		}
+		report.Products = append(report.Products, usage)
+	}
+	return report
+}
+
+// Table renders the capacity report as a text table
+func (r *CapacityReport) Table() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	row := func(kind string, u CapacityUsage) {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t\n", kind, u.Name, u.Instances, u.CPU, u.MemoryMB, u.EphemeralDiskMB, u.PersistentDiskMB)
+	}
+	fmt.Fprintln(w, "\tNAME\tINSTANCES\tCPU\tMEMORY MB\tEPHEMERAL DISK MB\tPERSISTENT DISK MB\t")
+	for _, u := range r.Products {
+		row("product", u)
+	}
+	for _, u := range r.AvailabilityZones {
+		row("az", u)
+	}
+	row("", r.Total)
+	w.Flush()
+	fmt.Fprintf(&buf, "diego cells: %d, container memory: %d MB, container disk: %d MB\n", r.DiegoCells.Cells, r.DiegoCells.MemoryMB, r.DiegoCells.DiskMB)
+	return buf.String()
+}
+
+func (u *CapacityUsage) add(job *Jobs, instances int) {
+	u.Instances += instances
+	u.CPU += instances * job.resource("cpu")
+	u.MemoryMB += instances * job.resource("ram")
+	u.EphemeralDiskMB += instances * job.resource("ephemeral_disk")
+	u.PersistentDiskMB += instances * job.resource("persistent_disk")
+}
+
+// resource returns the value of a resource of the job, or 0 when it has none
+func (j *Jobs) resource(identifier string) int {
+	for _, r := range j.Resources {
+		if r.Identifier == identifier {
+			return r.Value
+		}
+	}
+	return 0
+}
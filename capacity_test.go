@@ -0,0 +1,46 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Capacity", func() {
+	var report *CapacityReport
+
+	BeforeEach(func() {
+		report = NewInstallationSettingsJSON(fixture("installation_settings.json")).Capacity()
+	})
+
+	It("sums the resources of the job instances per product", func() {
+		Expect(report.Products).To(Equal([]CapacityUsage{
+			{Name: "p-bosh", Instances: 1, CPU: 2, MemoryMB: 3072, EphemeralDiskMB: 25600, PersistentDiskMB: 40960},
+			{Name: "cf", Instances: 25, CPU: 29, MemoryMB: 82944, EphemeralDiskMB: 292144, PersistentDiskMB: 217760},
+		}))
+		Expect(report.Total).To(Equal(CapacityUsage{Name: "total", Instances: 26, CPU: 31, MemoryMB: 86016, EphemeralDiskMB: 317744, PersistentDiskMB: 258720}))
+	})
+
+	It("sums the resources of the job instances per availability zone", func() {
+		Expect(report.AvailabilityZones).To(Equal([]CapacityUsage{
+			{Name: "PCF-Capacity-01", Instances: 23, CPU: 27, MemoryMB: 67584, EphemeralDiskMB: 248112, PersistentDiskMB: 258720},
+			{Name: "PCF-Capacity-02", Instances: 3, CPU: 4, MemoryMB: 18432, EphemeralDiskMB: 69632, PersistentDiskMB: 0},
+		}))
+	})
+
+	It("reports the Diego cell container capacity", func() {
+		Expect(report.DiegoCells).To(Equal(DiegoCellCapacity{
+			Cells:             3,
+			MemoryMB:          49152,
+			DiskMB:            196608,
+			AvailabilityZones: map[string]int{"PCF-Capacity-01": 2, "PCF-Capacity-02": 1},
+		}))
+	})
+
+	It("renders a table", func() {
+		table := report.Table()
+		Expect(table).To(ContainSubstring("MEMORY MB"))
+		Expect(table).To(MatchRegexp(`product\s+cf\s+25\s+29\s+82944\s+292144\s+217760`))
+		Expect(table).To(ContainSubstring("diego cells: 3, container memory: 49152 MB, container disk: 196608 MB"))
+	})
+})
@@ -0,0 +1,202 @@
+package opsmanclient
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Certificate rotation steps reported by RotateCertificates
+const (
+	RotationAwaitingApplyChanges = "awaiting-apply-changes"
+	RotationNewAuthorityAdded    = "new-authority-added"
+	RotationNewAuthorityActive   = "new-authority-activated"
+	RotationComplete             = "complete"
+)
+
+// GenerateCertificate creates a certificate signed by the active Ops Manager
+// root CA for the given domains
+func (c *OpsManAPI) GenerateCertificate(domains ...string) (*Certificate, error) {
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("at least one domain is required to generate a certificate")
+	}
+
+	var cert Certificate
+	req := map[string][]string{"domains": domains}
+	if err := c.apiRequest("POST", "v0/certificates/generate", req, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// ListCertificateAuthorities returns all the certificate authorities known to Ops Manager
+func (c *OpsManAPI) ListCertificateAuthorities() ([]CertificateAuthority, error) {
+	var resp struct {
+		CertificateAuthorities []CertificateAuthority `json:"certificate_authorities"`
+	}
+	if err := c.apiRequest("GET", "v0/certificate_authorities", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.CertificateAuthorities, nil
+}
+
+// CreateCertificateAuthority uploads a custom certificate authority. The new
+// authority is inactive until ActivateCertificateAuthority is called.
+func (c *OpsManAPI) CreateCertificateAuthority(certPEM, privateKeyPEM string) (*CertificateAuthority, error) {
+	var ca CertificateAuthority
+	req := map[string]string{
+		"cert_pem":        certPEM,
+		"private_key_pem": privateKeyPEM,
+	}
+	if err := c.apiRequest("POST", "v0/certificate_authorities", req, &ca); err != nil {
+		return nil, err
+	}
+	return &ca, nil
+}
+
+// GenerateCertificateAuthority has Ops Manager generate a new, inactive, certificate authority
+func (c *OpsManAPI) GenerateCertificateAuthority() (*CertificateAuthority, error) {
+	var ca CertificateAuthority
+	if err := c.apiRequest("POST", "v0/certificate_authorities/generate", nil, &ca); err != nil {
+		return nil, err
+	}
+	return &ca, nil
+}
+
+// ActivateCertificateAuthority makes the certificate authority with the given GUID the active one
+func (c *OpsManAPI) ActivateCertificateAuthority(guid string) error {
+	return c.apiRequest("POST", fmt.Sprintf("v0/certificate_authorities/%s/activate", guid), nil, nil)
+}
+
+// DeleteCertificateAuthority removes an inactive certificate authority
+func (c *OpsManAPI) DeleteCertificateAuthority(guid string) error {
+	return c.apiRequest("DELETE", fmt.Sprintf("v0/certificate_authorities/%s", guid), nil, nil)
+}
+
+// RegenerateCertificates regenerates all the non-configurable certificates
+// with the active certificate authority
+func (c *OpsManAPI) RegenerateCertificates() error {
+	return c.apiRequest("POST", "v0/certificate_authorities/active/regenerate", nil, nil)
+}
+
+// GetPendingChanges returns the staged change of every product
+func (c *OpsManAPI) GetPendingChanges() ([]ProductChange, error) {
+	var resp struct {
+		ProductChanges []ProductChange `json:"product_changes"`
+	}
+	if err := c.apiRequest("GET", "v0/staged/pending_changes", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.ProductChanges, nil
+}
+
+// RotateCertificates guides the rotation of the Ops Manager root CA. Every
+// call performs the next step of the rotation and each step has to be followed
+// by Apply Changes for the products listed in the result:
+//
+//  1. a new certificate authority is added, created from certPEM and
+//     privateKeyPEM or generated by Ops Manager when they are empty
+//  2. the new certificate authority is activated and the non-configurable
+//     certificates are regenerated
+//  3. the previous certificate authority is deleted
+//
+// No step is taken while products still have pending changes.
+func (c *OpsManAPI) RotateCertificates(certPEM, privateKeyPEM string) (*CertificateRotation, error) {
+	pending, err := c.productsRequiringApplyChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	cas, err := c.ListCertificateAuthorities()
+	if err != nil {
+		return nil, err
+	}
+	active, inactive := splitCertificateAuthorities(cas)
+	if active == nil {
+		return nil, fmt.Errorf("no active certificate authority found")
+	}
+
+	rotation := &CertificateRotation{ActiveAuthority: active}
+	if len(pending) > 0 {
+		rotation.Step = RotationAwaitingApplyChanges
+		rotation.ProductsRequiringApplyChanges = pending
+		return rotation, nil
+	}
+
+	var newer, older *CertificateAuthority
+	for i := range inactive {
+		if inactive[i].CreatedOn > active.CreatedOn {
+			newer = &inactive[i]
+		} else {
+			older = &inactive[i]
+		}
+	}
+
+	switch {
+	case newer != nil:
+		c.logger.Debugf("activating certificate authority %s", newer.GUID)
+		if err = c.ActivateCertificateAuthority(newer.GUID); err != nil {
+			return nil, err
+		}
+		if err = c.RegenerateCertificates(); err != nil {
+			return nil, err
+		}
+		newer.Active = true
+		rotation.Step = RotationNewAuthorityActive
+		rotation.PreviousAuthority = active
+		rotation.ActiveAuthority = newer
+
+	case older != nil:
+		c.logger.Debugf("deleting certificate authority %s", older.GUID)
+		if err = c.DeleteCertificateAuthority(older.GUID); err != nil {
+			return nil, err
+		}
+		rotation.Step = RotationComplete
+		rotation.PreviousAuthority = older
+		return rotation, nil
+
+	default:
+		if certPEM != "" || privateKeyPEM != "" {
+			rotation.NewAuthority, err = c.CreateCertificateAuthority(certPEM, privateKeyPEM)
+		} else {
+			rotation.NewAuthority, err = c.GenerateCertificateAuthority()
+		}
+		if err != nil {
+			return nil, err
+		}
+		rotation.Step = RotationNewAuthorityAdded
+	}
+
+	if rotation.ProductsRequiringApplyChanges, err = c.productsRequiringApplyChanges(); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+func (c *OpsManAPI) productsRequiringApplyChanges() ([]string, error) {
+	changes, err := c.GetPendingChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var products []string
+	for _, change := range changes {
+		if change.Action != "unchanged" {
+			products = append(products, change.GUID)
+		}
+	}
+	sort.Strings(products)
+	return products, nil
+}
+
+func splitCertificateAuthorities(cas []CertificateAuthority) (*CertificateAuthority, []CertificateAuthority) {
+	var active *CertificateAuthority
+	var inactive []CertificateAuthority
+	for i := range cas {
+		if cas[i].Active {
+			active = &cas[i]
+		} else {
+			inactive = append(inactive, cas[i])
+		}
+	}
+	return active, inactive
+}
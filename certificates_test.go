@@ -0,0 +1,121 @@
+package opsmanclient_test
+
+import (
+	nhttp "net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Certificates", func() {
+	var (
+		err      error
+		cas      []opsmanclient.CertificateAuthority
+		pending  []opsmanclient.ProductChange
+		rotation *opsmanclient.CertificateRotation
+	)
+
+	BeforeEach(func() {
+		cas = []opsmanclient.CertificateAuthority{
+			{GUID: "ca-guid-1", Issuer: "Pivotal", CreatedOn: "2016-01-01T00:00:00Z", Active: true},
+		}
+		pending = []opsmanclient.ProductChange{
+			{GUID: "p-bosh-guid", Action: "unchanged"},
+			{GUID: "cf-guid", Action: "unchanged"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		opsman.InitializeCertificatesTest(cas, pending)
+	})
+
+	Describe("generate certificate", func() {
+		It("sends the domains to ops manager", func() {
+			cert, err := c.GenerateCertificate("*.sys.example.com", "*.apps.example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Certificate).To(HavePrefix("-----BEGIN CERTIFICATE-----"))
+			Expect(opsman.GeneratedCertificateDomains).To(Equal([]string{"*.sys.example.com", "*.apps.example.com"}))
+		})
+
+		It("requires a domain", func() {
+			_, err := c.GenerateCertificate()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("list certificate authorities", func() {
+		It("returns the certificate authorities", func() {
+			cas, err := c.ListCertificateAuthorities()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cas).To(HaveLen(1))
+			Expect(cas[0].GUID).To(Equal("ca-guid-1"))
+			Expect(cas[0].Active).To(BeTrue())
+		})
+	})
+
+	Describe("delete certificate authority", func() {
+		It("requires an http client that supports DELETE requests", func() {
+			c.HTTPClient = nhttp.DefaultClient
+			err := c.DeleteCertificateAuthority("ca-guid-1")
+			Expect(err).To(MatchError("the http client does not support DELETE requests"))
+		})
+	})
+
+	Describe("rotate certificates", func() {
+		JustBeforeEach(func() {
+			rotation, err = c.RotateCertificates("", "")
+		})
+
+		Context("when there are no pending changes", func() {
+			It("adds a new certificate authority", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotation.Step).To(Equal(opsmanclient.RotationNewAuthorityAdded))
+				Expect(rotation.NewAuthority.GUID).To(Equal("ca-guid-2"))
+				Expect(rotation.ProductsRequiringApplyChanges).To(Equal([]string{"p-bosh-guid"}))
+				Expect(opsman.CertificateAuthorities).To(HaveLen(2))
+			})
+		})
+
+		Context("when a newer certificate authority was added", func() {
+			BeforeEach(func() {
+				cas = append(cas, opsmanclient.CertificateAuthority{GUID: "ca-guid-2", CreatedOn: "2017-01-01T00:00:00Z"})
+			})
+
+			It("activates it and regenerates the certificates", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotation.Step).To(Equal(opsmanclient.RotationNewAuthorityActive))
+				Expect(rotation.PreviousAuthority.GUID).To(Equal("ca-guid-1"))
+				Expect(rotation.ActiveAuthority.GUID).To(Equal("ca-guid-2"))
+				Expect(rotation.ProductsRequiringApplyChanges).To(Equal([]string{"cf-guid"}))
+				Expect(opsman.RegenerateCertificatesCalls).To(Equal(1))
+			})
+		})
+
+		Context("when the new certificate authority is active", func() {
+			BeforeEach(func() {
+				cas = append(cas, opsmanclient.CertificateAuthority{GUID: "ca-guid-0", CreatedOn: "2015-01-01T00:00:00Z"})
+			})
+
+			It("deletes the previous certificate authority", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotation.Step).To(Equal(opsmanclient.RotationComplete))
+				Expect(rotation.PreviousAuthority.GUID).To(Equal("ca-guid-0"))
+				Expect(opsman.CertificateAuthorities).To(HaveLen(1))
+			})
+		})
+
+		Context("when products have pending changes", func() {
+			BeforeEach(func() {
+				pending[1].Action = "update"
+			})
+
+			It("waits for apply changes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotation.Step).To(Equal(opsmanclient.RotationAwaitingApplyChanges))
+				Expect(rotation.ProductsRequiringApplyChanges).To(Equal([]string{"cf-guid"}))
+				Expect(opsman.CertificateAuthorities).To(HaveLen(1))
+			})
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package opsmanclient
+
+// Database engines
+const (
+	DatabaseEnginePostgres = "postgres"
+	DatabaseEngineMySQL    = "mysql"
+	DatabaseEngineExternal = "external"
+)
+
+const (
+	// postgresPort is the port the Elastic Runtime postgres jobs listen on
+	postgresPort = 5524
+	// mysqlPort is the port of the internal MySQL cluster proxies
+	mysqlPort = 3306
+)
+
+// logicalDatabase describes where a logical Elastic Runtime database can run
+type logicalDatabase struct {
+	name             string
+	job              string
+	nameProperty     string
+	mysqlCredentials string
+	defaultDatabase  string
+}
+
+var logicalDatabases = []logicalDatabase{
+	{name: "ccdb", job: "ccdb", nameProperty: "ccdb_database", mysqlCredentials: "ccdb_credentials", defaultDatabase: "ccdb"},
+	{name: "uaadb", job: "uaadb", nameProperty: "uaa_database", mysqlCredentials: "uaadb_credentials", defaultDatabase: "uaa"},
+	{name: "consoledb", job: "consoledb", nameProperty: "console_database", mysqlCredentials: "consoledb_credentials", defaultDatabase: "console"},
+}
+
+// DatabaseTopology returns where the ccdb, uaadb and consoledb databases of the
+// Elastic Runtime product run: on its internal postgres jobs, on its internal
+// MySQL cluster, or on an external database server, as selected by its
+// system_database property
+func (is *InstallationSettings) DatabaseTopology() ([]Database, error) {
+	product, err := is.FindProduct("cf")
+	if err != nil {
+		return nil, err
+	}
+
+	engine, external := systemDatabase(product)
+	var databases []Database
+	for _, logical := range logicalDatabases {
+		db := Database{
+			Name:         logical.name,
+			DatabaseName: logical.defaultDatabase,
+		}
+		if name, err := product.FindProperty(logical.nameProperty); err == nil {
+			if value, err := name.AsString(); err == nil && value != "" {
+				db.DatabaseName = value
+			}
+		}
+
+		db.Engine = engine
+		if db.Engine == "" {
+			db.Engine = DatabaseEngineMySQL
+			if job, err := product.FindJob(logical.job); err == nil && job.instanceCount() > 0 {
+				db.Engine = DatabaseEnginePostgres
+			}
+		}
+		switch db.Engine {
+		case DatabaseEnginePostgres:
+			db.Jobs = []string{logical.job}
+			db.Hosts = is.jobIPs(product, logical.job)
+			db.Port = postgresPort
+			db.CredentialReference = credentialReference(product, logical.job, "credentials")
+		case DatabaseEngineMySQL:
+			db.Jobs = []string{"mysql_proxy", "mysql"}
+			db.Hosts = is.jobIPs(product, "mysql_proxy")
+			db.Port = mysqlPort
+			db.CredentialReference = credentialReference(product, "mysql", logical.mysqlCredentials)
+		case DatabaseEngineExternal:
+			if host, ok := findProperty(external, "host"); ok {
+				if value, err := host.AsString(); err == nil && value != "" {
+					db.Hosts = []string{value}
+				}
+			}
+			if port, ok := findProperty(external, "port"); ok {
+				db.Port, _ = port.AsInt()
+			}
+			if password, ok := findProperty(external, "password"); ok {
+				db.CredentialReference = passwordReference(secretPath(product, nil, "system_database.external.password"), password)
+			}
+		}
+		databases = append(databases, db)
+	}
+	return databases, nil
+}
+
+// systemDatabase returns the engine selected by the system_database property,
+// or "" when the product has no such property, and the external database
+// properties
+func systemDatabase(product *Products) (string, []Properties) {
+	property, err := product.FindProperty("system_database")
+	if err != nil {
+		return "", nil
+	}
+	selector, err := property.AsSelector()
+	if err != nil {
+		return "", nil
+	}
+	var external []Properties
+	for _, option := range property.Options {
+		if option.Identifier == "external" {
+			external = option.Properties
+		}
+	}
+	switch selector.Value {
+	case "internal":
+		return DatabaseEnginePostgres, external
+	case "external":
+		return DatabaseEngineExternal, external
+	}
+	return DatabaseEngineMySQL, external
+}
+
+// credentialReference returns the secret path of the password of a credential
+// property of a job, or "" when the job has no such property
+func credentialReference(product *Products, job, property string) string {
+	j, err := product.FindJob(job)
+	if err != nil {
+		return ""
+	}
+	p, err := j.FindProperty(property)
+	if err != nil {
+		return ""
+	}
+	return passwordReference(secretPath(product, j, property), p)
+}
+
+// passwordReference returns the secret path of the password a property holds,
+// which is its password member for credentials
+func passwordReference(path string, property *Properties) string {
+	if m, ok := property.Value.(map[string]interface{}); ok {
+		if _, ok := m["password"]; ok {
+			return path + ".password"
+		}
+	}
+	return path
+}
+
+func (is *InstallationSettings) jobIPs(product *Products, job string) []string {
+	instances, _ := product.JobInstances(job, &is.Infrastructure)
+	var ips []string
+	for _, instance := range instances {
+		if instance.IP != "" {
+			ips = append(ips, instance.IP)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,97 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Database topology", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("finds the databases on the internal MySQL cluster", func() {
+		databases, err := is.DatabaseTopology()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(databases).To(HaveLen(3))
+		Expect(databases[0]).To(Equal(Database{
+			Name:                "ccdb",
+			Engine:              DatabaseEngineMySQL,
+			Jobs:                []string{"mysql_proxy", "mysql"},
+			Hosts:               []string{"192.168.200.18"},
+			Port:                3306,
+			DatabaseName:        "ccdb",
+			CredentialReference: "cf/mysql/ccdb_credentials.password",
+		}))
+		Expect(databases[1].DatabaseName).To(Equal("uaa"))
+		Expect(databases[2].CredentialReference).To(Equal("cf/mysql/consoledb_credentials.password"))
+	})
+
+	It("finds the databases on the internal postgres jobs", func() {
+		Expect(is.SetProperty("cf", "", "system_database", "internal")).To(Succeed())
+		databases, err := is.DatabaseTopology()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(databases[1]).To(Equal(Database{
+			Name:                "uaadb",
+			Engine:              DatabaseEnginePostgres,
+			Jobs:                []string{"uaadb"},
+			Hosts:               []string{"192.168.200.22"},
+			Port:                5524,
+			DatabaseName:        "uaa",
+			CredentialReference: "cf/uaadb/credentials.password",
+		}))
+	})
+
+	It("finds external databases", func() {
+		Expect(is.SetProperty("cf", "", "system_database", "external")).To(Succeed())
+		product, _ := is.FindProduct("cf")
+		database, _ := product.FindProperty("system_database")
+		selector, err := database.AsSelector()
+		Expect(err).NotTo(HaveOccurred())
+		port, _ := CollectionItem(selector.Properties).Get("port")
+		port.Value = 5432
+		host, _ := CollectionItem(selector.Properties).Get("host")
+		host.Value = "db.example.com"
+
+		databases, err := is.DatabaseTopology()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(databases[2]).To(Equal(Database{
+			Name:                "consoledb",
+			Engine:              DatabaseEngineExternal,
+			Hosts:               []string{"db.example.com"},
+			Port:                5432,
+			DatabaseName:        "console",
+			CredentialReference: "cf/system_database.external.password",
+		}))
+	})
+
+	It("references credentials the secret store holds", func() {
+		for _, engine := range []string{"internal_mysql", "internal", "external"} {
+			Expect(is.SetProperty("cf", "", "system_database", engine)).To(Succeed())
+			product, _ := is.FindProduct("cf")
+			database, _ := product.FindProperty("system_database")
+			selector, err := database.AsSelector()
+			Expect(err).NotTo(HaveOccurred())
+			if password, ok := CollectionItem(selector.Properties).Get("password"); ok {
+				password.Value = "D4tab4seP4ss"
+			}
+
+			databases, err := is.DatabaseTopology()
+			Expect(err).NotTo(HaveOccurred())
+			_, secrets, err := ExtractSecrets(is)
+			Expect(err).NotTo(HaveOccurred())
+			for _, db := range databases {
+				Expect(secrets).To(HaveKey(db.CredentialReference), engine)
+			}
+		}
+	})
+
+	It("fails without an Elastic Runtime product", func() {
+		is.Products = is.Products[:1]
+		_, err := is.DatabaseTopology()
+		Expect(err).To(MatchError("product cf not found"))
+	})
+})
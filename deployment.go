@@ -1,81 +1,192 @@
 package opsmanclient
 
+import (
+	"fmt"
+	"sort"
+)
+
+// Application runtime types of a deployment
+const (
+	RuntimeDiego = "diego"
+	RuntimeDEA   = "dea"
+	RuntimeMixed = "mixed"
+	RuntimeNone  = "none"
+)
+
+// runtimeBackends are the cell, control and database jobs of the application
+// runtime backends of Elastic Runtime
+var runtimeBackends = []RuntimeBackend{
+	{Name: RuntimeDiego, CellJob: "diego_cell", ControlJobs: []string{"diego_brain"}, DatabaseJobs: []string{"diego_database"}},
+	{Name: RuntimeDEA, CellJob: "dea", ControlJobs: []string{"health_manager"}, DatabaseJobs: []string{"etcd_server"}},
+}
+
 // NewDeployment creates a new installation deployment
 func NewDeployment(installation *InstallationSettings, cfRelease string) *Deployment {
-
-	var uaaJobParts []string
-	for _, p := range getPartitions(installation, cfRelease, "uaa") {
-		if p.InstanceCount > 0 {
-			uaaJobParts = append(uaaJobParts, p.InstallationName)
-		}
+	deployment := &Deployment{
+		Release: cfRelease,
+		Jobs:    make(map[string][]Partition),
 	}
-	var routerJobParts []string
-	for _, p := range getPartitions(installation, cfRelease, "router") {
-		if p.InstanceCount > 0 {
-			routerJobParts = append(routerJobParts, p.InstallationName)
+	for i := range installation.Products {
+		product := &installation.Products[i]
+		if product.Name != cfRelease {
+			continue
 		}
-	}
-	var ccJobParts []string
-	for _, p := range getPartitions(installation, cfRelease, "cloud_controller") {
-		if p.InstanceCount > 0 {
-			ccJobParts = append(ccJobParts, p.InstallationName)
+		deployment.ProductType = product.Identifier
+		deployment.ProductVersion = product.ProductVersion
+		deployment.Stemcell = product.Stemcell
+		deployment.product = product
+		deployment.infrastructure = &installation.Infrastructure
+		for j := range product.Jobs {
+			job := &product.Jobs[j]
+			var active []Partition
+			for _, p := range job.Partition {
+				if p.InstanceCount > 0 {
+					active = append(active, p)
+				}
+			}
+			deployment.Jobs[jobName(job)] = active
 		}
 	}
-	var diegoBrainParts []string
-	for _, p := range getPartitions(installation, cfRelease, "diego_brain") {
-		if p.InstanceCount > 0 {
-			diegoBrainParts = append(diegoBrainParts, p.InstallationName)
-		}
+
+	deployment.UaaJobs = deployment.PartitionNames("uaa")
+	deployment.RouterJobs = deployment.PartitionNames("router")
+	deployment.CloudControllerJobs = deployment.PartitionNames("cloud_controller")
+	deployment.CloudControllerDatabaseJobs = deployment.PartitionNames("ccdb")
+	deployment.DiegoBrainJobs = deployment.PartitionNames("diego_brain")
+	deployment.DiegoCellJobs = deployment.PartitionNames("diego_cell")
+	deployment.DiegoDatabaseJobs = deployment.PartitionNames("diego_database")
+	deployment.UaaDatabaseJobs = deployment.PartitionNames("uaadb")
+	deployment.DEAJobs = deployment.PartitionNames("dea")
+	return deployment
+}
+
+// JobNames returns the identifiers of all the jobs of the deployment
+func (d *Deployment) JobNames() []string {
+	names := make([]string, 0, len(d.Jobs))
+	for name := range d.Jobs {
+		names = append(names, name)
 	}
-	var diegoCellParts []string
-	for _, p := range getPartitions(installation, cfRelease, "diego_cell") {
-		if p.InstanceCount > 0 {
-			diegoCellParts = append(diegoCellParts, p.InstallationName)
-		}
+	sort.Strings(names)
+	return names
+}
+
+// PartitionNames returns the installation names of the active partitions of a job
+func (d *Deployment) PartitionNames(job string) []string {
+	var names []string
+	for _, p := range d.Jobs[job] {
+		names = append(names, p.InstallationName)
 	}
-	var diegoDatabaseParts []string
-	for _, p := range getPartitions(installation, cfRelease, "diego_database") {
-		if p.InstanceCount > 0 {
-			diegoDatabaseParts = append(diegoDatabaseParts, p.InstallationName)
-		}
+	return names
+}
+
+// InstanceCount returns the number of instances of a job
+func (d *Deployment) InstanceCount(job string) int {
+	count := 0
+	for _, p := range d.Jobs[job] {
+		count += p.InstanceCount
 	}
-	var uaaDatabaseParts []string
-	for _, p := range getPartitions(installation, cfRelease, "uaadb") {
-		if p.InstanceCount > 0 {
-			uaaDatabaseParts = append(uaaDatabaseParts, p.InstallationName)
+	return count
+}
+
+// AvailabilityZones returns the names of the availability zones a job has instances in
+func (d *Deployment) AvailabilityZones(job string) []string {
+	var azs []string
+	for _, p := range d.Jobs[job] {
+		az := p.AvailabilityZoneReference
+		if d.infrastructure != nil {
+			if zone := d.infrastructure.findAvailabilityZone(az); zone != nil {
+				az = zone.Name
+			}
 		}
+		azs = append(azs, az)
 	}
-	var ccJobDatabaseParts []string
-	for _, p := range getPartitions(installation, cfRelease, "ccdb") {
-		if p.InstanceCount > 0 {
-			ccJobDatabaseParts = append(ccJobDatabaseParts, p.InstallationName)
+	return azs
+}
+
+// Runtime returns the application runtime backends with active cells, with
+// their cell counts per availability zone and their active control and
+// database jobs. The runtime type is diego, dea, mixed when both have cells, or
+// none.
+func (d *Deployment) Runtime() *Runtime {
+	runtime := &Runtime{Type: RuntimeNone}
+	for _, backend := range runtimeBackends {
+		cells := d.InstanceCount(backend.CellJob)
+		if cells == 0 {
+			continue
+		}
+		active := RuntimeBackend{
+			Name:                    backend.Name,
+			CellJob:                 backend.CellJob,
+			Cells:                   cells,
+			CellsByAvailabilityZone: make(map[string]int),
+			ControlJobs:             d.activeJobs(backend.ControlJobs),
+			DatabaseJobs:            d.activeJobs(backend.DatabaseJobs),
 		}
+		azs := d.AvailabilityZones(backend.CellJob)
+		for i, p := range d.Jobs[backend.CellJob] {
+			active.CellsByAvailabilityZone[azs[i]] += p.InstanceCount
+		}
+		runtime.Backends = append(runtime.Backends, active)
 	}
 
-	deployment := &Deployment{
-		Release:                     cfRelease,
-		UaaJobs:                     uaaJobParts,
-		RouterJobs:                  routerJobParts,
-		CloudControllerJobs:         ccJobParts,
-		CloudControllerDatabaseJobs: ccJobDatabaseParts,
-		DiegoBrainJobs:              diegoBrainParts,
-		DiegoCellJobs:               diegoCellParts,
-		DiegoDatabaseJobs:           diegoDatabaseParts,
-		UaaDatabaseJobs:             uaaDatabaseParts,
+	switch len(runtime.Backends) {
+	case 1:
+		runtime.Type = runtime.Backends[0].Name
+	case 2:
+		runtime.Type = RuntimeMixed
 	}
+	return runtime
+}
 
-	return deployment
+func (d *Deployment) activeJobs(jobs []string) []string {
+	var active []string
+	for _, job := range jobs {
+		if d.InstanceCount(job) > 0 {
+			active = append(active, job)
+		}
+	}
+	return active
 }
 
-func getPartitions(installation *InstallationSettings, productName, jobInstallationName string) []Partition {
-	for _, product := range installation.Products {
-		if product.Name == productName {
-			for _, job := range product.Jobs {
-				if job.InstallationName == jobInstallationName {
-					return job.Partition
-				}
+// Instances returns the instances of a job with their IP addresses
+func (d *Deployment) Instances(job string) ([]JobInstance, error) {
+	if d.product == nil {
+		return nil, fmt.Errorf("product %s not found in the installation settings", d.Release)
+	}
+	return d.product.JobInstances(job, d.infrastructure)
+}
+
+// JobInstances returns the instances of a job with the IP addresses the ips map
+// holds for their partition, and the name of their availability zone, which
+// requires the infrastructure
+func (p *Products) JobInstances(job string, infrastructure *Infrastructure) ([]JobInstance, error) {
+	if infrastructure == nil {
+		return nil, fmt.Errorf("the infrastructure is needed to list the instances of job %s", job)
+	}
+	j, err := p.FindJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []JobInstance
+	for _, partition := range j.Partition {
+		az := partition.AvailabilityZoneReference
+		if zone := infrastructure.findAvailabilityZone(az); zone != nil {
+			az = zone.Name
+		}
+		ips := p.IPS[j.GUID+"-partition-"+partition.AvailabilityZoneReference]
+		for i := 0; i < partition.InstanceCount; i++ {
+			instance := JobInstance{
+				Job:              jobName(j),
+				Index:            len(instances),
+				AvailabilityZone: az,
+				Partition:        partition.InstallationName,
+			}
+			if i < len(ips) {
+				instance.IP = ips[i]
 			}
+			instances = append(instances, instance)
 		}
 	}
-	return nil
+	return instances, nil
 }
@@ -39,6 +39,42 @@ var _ = Describe("Deployment", func() {
 				Expect(deployment.UaaJobs).To(HaveLen(1))
 			})
 
+			It("should expose every job with its active partitions", func() {
+				Expect(deployment.JobNames()).To(ContainElement("nats"))
+				Expect(deployment.JobNames()).To(ContainElement("ha_proxy"))
+				Expect(deployment.JobNames()).To(ContainElement("doppler"))
+				Expect(deployment.Jobs["diego_cell"]).To(HaveLen(2))
+				Expect(deployment.Jobs["dea"]).To(BeEmpty())
+				Expect(deployment.PartitionNames("router")).To(Equal(deployment.RouterJobs))
+			})
+
+			It("should resolve the IP addresses of the job instances", func() {
+				instances, err := deployment.Instances("ccdb")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances).To(Equal([]JobInstance{
+					{Job: "ccdb", Index: 0, IP: "192.168.200.21", AvailabilityZone: "PCF-Capacity-01", Partition: "ccdb-partition-e6f2e103df59e642f38b"},
+				}))
+
+				instances, err = deployment.Instances("diego_cell")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances).To(HaveLen(3))
+				Expect(instances[2].Index).To(Equal(2))
+				Expect(instances[2].IP).To(Equal("192.168.200.32"))
+				Expect(instances[2].AvailabilityZone).To(Equal("PCF-Capacity-02"))
+			})
+
+			It("should fail for unknown jobs", func() {
+				_, err := deployment.Instances("p-mysql")
+				Expect(err).To(MatchError("job p-mysql not found in product cf-6455120728b109a1086c"))
+			})
+
+			It("should fail without the infrastructure", func() {
+				product, err := is.FindProduct("cf")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = product.JobInstances("ccdb", nil)
+				Expect(err).To(MatchError("the infrastructure is needed to list the instances of job ccdb"))
+			})
+
 			// It("should not error", func() {
 			// 	Expect(err).NotTo(HaveOccurred())
 			// })
@@ -46,6 +82,84 @@ var _ = Describe("Deployment", func() {
 	})
 })
 
+var _ = Describe("Runtime", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("detects a Diego runtime", func() {
+		runtime := NewDeployment(is, "cf-6455120728b109a1086c").Runtime()
+		Expect(runtime.Type).To(Equal(RuntimeDiego))
+		Expect(runtime.Backends).To(Equal([]RuntimeBackend{{
+			Name:                    "diego",
+			CellJob:                 "diego_cell",
+			Cells:                   3,
+			CellsByAvailabilityZone: map[string]int{"PCF-Capacity-01": 2, "PCF-Capacity-02": 1},
+			ControlJobs:             []string{"diego_brain"},
+			DatabaseJobs:            []string{"diego_database"},
+		}}))
+	})
+
+	It("detects a mixed runtime", func() {
+		Expect(is.SetInstanceCount("cf", "dea", 2)).To(Succeed())
+		deployment := NewDeployment(is, "cf-6455120728b109a1086c")
+		runtime := deployment.Runtime()
+		Expect(runtime.Type).To(Equal(RuntimeMixed))
+		Expect(runtime.Backends[1].Name).To(Equal(RuntimeDEA))
+		Expect(runtime.Backends[1].CellsByAvailabilityZone).To(Equal(map[string]int{"PCF-Capacity-01": 1, "PCF-Capacity-02": 1}))
+		Expect(runtime.Backends[1].DatabaseJobs).To(Equal([]string{"etcd_server"}))
+		Expect(deployment.DEAJobs).To(HaveLen(2))
+	})
+
+	It("detects a DEA runtime", func() {
+		Expect(is.SetInstanceCount("cf", "dea", 1)).To(Succeed())
+		Expect(is.SetInstanceCount("cf", "diego_cell", 0)).To(Succeed())
+		Expect(NewDeployment(is, "cf-6455120728b109a1086c").Runtime().Type).To(Equal(RuntimeDEA))
+	})
+
+	It("reports no runtime without cells", func() {
+		Expect(is.SetInstanceCount("cf", "diego_cell", 0)).To(Succeed())
+		runtime := NewDeployment(is, "cf-6455120728b109a1086c").Runtime()
+		Expect(runtime.Type).To(Equal(RuntimeNone))
+		Expect(runtime.Backends).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GetDeployment", func() {
+	BeforeEach(func() {
+		opsman.InitializeInstallationSettingsTest(fixture("installation_settings.json"))
+	})
+
+	It("returns the deployment of any product", func() {
+		deployment, err := c.GetDeployment("p-bosh")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.Release).To(Equal("p-bosh-09d124d6b628e30e02e1"))
+		Expect(deployment.ProductType).To(Equal("p-bosh"))
+		Expect(deployment.Stemcell.Version).To(Equal("3100"))
+		Expect(deployment.JobNames()).To(Equal([]string{"director"}))
+		Expect(deployment.InstanceCount("director")).To(Equal(1))
+		Expect(deployment.AvailabilityZones("director")).To(Equal([]string{"PCF-Capacity-01"}))
+
+		instances, err := deployment.Instances("director")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(HaveLen(1))
+	})
+
+	It("counts the instances of a job over its availability zones", func() {
+		deployment, err := c.GetDeployment("cf")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.InstanceCount("diego_cell")).To(Equal(3))
+		Expect(deployment.AvailabilityZones("diego_cell")).To(Equal([]string{"PCF-Capacity-01", "PCF-Capacity-02"}))
+	})
+
+	It("fails for products that are not installed", func() {
+		_, err := c.GetDeployment("p-redis")
+		Expect(err).To(MatchError("product p-redis not found"))
+	})
+})
+
 func NewInstallationSettingsJSON(metadata string) *InstallationSettings {
 	resp := bytes.NewBufferString(metadata)
 	decoder := json.NewDecoder(resp)
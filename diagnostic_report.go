@@ -0,0 +1,60 @@
+package opsmanclient
+
+import (
+	nhttp "net/http"
+	"sort"
+)
+
+// GetDiagnosticReport returns the Ops Manager diagnostic report. Ops Managers
+// without the diagnostic report endpoint get a report synthesized from their
+// installation settings.
+func (c *OpsManAPI) GetDiagnosticReport() (*DiagnosticReport, error) {
+	var report DiagnosticReport
+	err := c.apiRequest("GET", "v0/diagnostic_report", nil, &report)
+	if err == nil {
+		return &report, nil
+	}
+	if !isStatus(err, nhttp.StatusNotFound) {
+		return nil, err
+	}
+
+	c.logger.Debug("diagnostic report not available, falling back to installation settings")
+	installation, err := c.GetInstallationSettings()
+	if err != nil {
+		return nil, err
+	}
+	return NewDiagnosticReport(installation), nil
+}
+
+// NewDiagnosticReport synthesizes a diagnostic report from installation settings.
+// Every product is reported as staged and the prepared ones as deployed.
+func NewDiagnosticReport(installation *InstallationSettings) *DiagnosticReport {
+	report := &DiagnosticReport{
+		Versions: DiagnosticVersions{
+			InstallationSchemaVersion: installation.InstallationSchemaVersion,
+		},
+		InfrastructureType:    installation.Infrastructure.Type,
+		DirectorConfiguration: installation.Infrastructure.DirectorConfiguration,
+		Synthesized:           true,
+	}
+
+	stemcells := make(map[string]bool)
+	for _, product := range installation.Products {
+		p := DiagnosticProduct{
+			Name:     product.Identifier,
+			Version:  product.ProductVersion,
+			Stemcell: product.Stemcell.File,
+		}
+		report.AddedProducts.Staged = append(report.AddedProducts.Staged, p)
+		if product.Prepared {
+			report.AddedProducts.Deployed = append(report.AddedProducts.Deployed, p)
+		}
+		if p.Stemcell != "" && !stemcells[p.Stemcell] {
+			stemcells[p.Stemcell] = true
+			report.Stemcells = append(report.Stemcells, p.Stemcell)
+		}
+	}
+	sort.Strings(report.Stemcells)
+
+	return report
+}
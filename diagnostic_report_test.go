@@ -0,0 +1,57 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Diagnostic report", func() {
+	var (
+		err    error
+		report *opsmanclient.DiagnosticReport
+	)
+
+	Context("when ops manager provides the diagnostic report", func() {
+		JustBeforeEach(func() {
+			opsman.InitializeDiagnosticReportTest(&opsmanclient.DiagnosticReport{
+				Versions:           opsmanclient.DiagnosticVersions{ReleaseVersion: "1.7.0.0"},
+				Stemcells:          []string{"bosh-stemcell-3215-vsphere-esxi-ubuntu-trusty-go_agent.tgz"},
+				InfrastructureType: "vsphere",
+			})
+			report, err = c.GetDiagnosticReport()
+		})
+
+		It("returns the report", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Synthesized).To(BeFalse())
+			Expect(report.Versions.ReleaseVersion).To(Equal("1.7.0.0"))
+			Expect(report.Stemcells).To(HaveLen(1))
+		})
+	})
+
+	Context("when ops manager lacks the diagnostic report", func() {
+		JustBeforeEach(func() {
+			opsman.InitializeDiagnosticReportTest(nil)
+			opsman.InitializeInstallationSettingsTest(fixture("installation_settings.json"))
+			report, err = c.GetDiagnosticReport()
+		})
+
+		It("synthesizes the report from the installation settings", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Synthesized).To(BeTrue())
+			Expect(report.Versions.InstallationSchemaVersion).To(Equal("1.6"))
+			Expect(report.InfrastructureType).To(Equal("vsphere"))
+			Expect(report.DirectorConfiguration.ResurrectorEnabled).To(BeTrue())
+			Expect(report.Stemcells).To(Equal([]string{
+				"bosh-stemcell-3100-vsphere-esxi-ubuntu-trusty-go_agent.tgz",
+				"bosh-stemcell-3146.9-vsphere-esxi-ubuntu-trusty-go_agent.tgz",
+			}))
+			Expect(report.AddedProducts.Deployed).To(ContainElement(opsmanclient.DiagnosticProduct{
+				Name:     "cf",
+				Version:  "1.6.17-build.10",
+				Stemcell: "bosh-stemcell-3146.9-vsphere-esxi-ubuntu-trusty-go_agent.tgz",
+			}))
+		})
+	})
+})
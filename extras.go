@@ -0,0 +1,287 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Extras retains the members of a json object that a model does not know
+// about, along with the member order and the original json of the members it
+// does know, so that decoding and re-encoding a document leaves everything
+// that was not modified untouched. Copies of a model share their Extras until
+// one of them changes its members, which then gets its own copy, so changing
+// the extras of a copy never changes the original.
+type Extras struct {
+	decoded   bool
+	keys      []string
+	extra     map[string]json.RawMessage
+	known     map[string]json.RawMessage
+	canonical map[string][]byte
+	derived   map[string][]byte
+}
+
+// Extra returns the json of a member the model does not know about
+func (x *Extras) Extra(key string) (json.RawMessage, bool) {
+	raw, ok := x.extra[key]
+	return raw, ok
+}
+
+// ExtraKeys returns the names of the members the model does not know about,
+// in document order
+func (x *Extras) ExtraKeys() []string {
+	var keys []string
+	for _, key := range x.keys {
+		if _, ok := x.extra[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SetExtra adds or replaces a member the model does not know about
+func (x *Extras) SetExtra(key string, value json.RawMessage) {
+	extra := make(map[string]json.RawMessage, len(x.extra)+1)
+	for k, v := range x.extra {
+		extra[k] = v
+	}
+	if !x.hasKey(key) {
+		x.keys = append(x.keys[:len(x.keys):len(x.keys)], key)
+	}
+	extra[key] = value
+	x.extra = extra
+}
+
+func (x *Extras) hasKey(key string) bool {
+	for _, k := range x.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteExtra removes a member the model does not know about
+func (x *Extras) DeleteExtra(key string) {
+	if _, ok := x.extra[key]; !ok {
+		return
+	}
+	extra := make(map[string]json.RawMessage, len(x.extra))
+	for k, v := range x.extra {
+		if k != key {
+			extra[k] = v
+		}
+	}
+	x.extra = extra
+}
+
+// derive records the value a migration filled a member in with. The member is
+// only encoded when its value changes from the derived one.
+func (x *Extras) derive(key string, value interface{}) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	derived := make(map[string][]byte, len(x.derived)+1)
+	for k, v := range x.derived {
+		derived[k] = v
+	}
+	derived[key] = b
+	x.derived = derived
+}
+
+// Encode writes the installation settings as compact json. Unlike json.Marshal
+// it does not escape HTML characters, so the output of a decoded document that
+// was not modified is identical to the compacted input.
+func (is *InstallationSettings) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(is)
+}
+
+type objectField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+var objectFieldsCache = struct {
+	sync.Mutex
+	fields map[reflect.Type][]objectField
+}{fields: make(map[reflect.Type][]objectField)}
+
+// objectFields returns the json members of a struct type in declaration order
+func objectFields(t reflect.Type) []objectField {
+	objectFieldsCache.Lock()
+	defer objectFieldsCache.Unlock()
+
+	if fields, ok := objectFieldsCache.fields[t]; ok {
+		return fields
+	}
+
+	var fields []objectField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || f.Anonymous || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		field := objectField{name: parts[0], index: i}
+		if field.name == "" {
+			field.name = f.Name
+		}
+		for _, option := range parts[1:] {
+			field.omitEmpty = field.omitEmpty || option == "omitempty"
+		}
+		fields = append(fields, field)
+	}
+	objectFieldsCache.fields[t] = fields
+	return fields
+}
+
+// unmarshalObject decodes the json object in data into the struct pointed to
+// by v, recording everything needed by marshalObject in x. v must not
+// implement json.Unmarshaler itself.
+func unmarshalObject(data []byte, v interface{}, x *Extras) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected a json object, got %v", token)
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	byName := make(map[string]objectField)
+	for _, f := range objectFields(rv.Type()) {
+		byName[f.name] = f
+	}
+
+	*x = Extras{
+		decoded:   true,
+		keys:      []string{},
+		extra:     make(map[string]json.RawMessage),
+		known:     make(map[string]json.RawMessage),
+		canonical: make(map[string][]byte),
+	}
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		if _, seen := x.known[key]; !seen {
+			if _, seen = x.extra[key]; !seen {
+				x.keys = append(x.keys, key)
+			}
+		}
+
+		f, ok := byName[key]
+		if !ok {
+			x.extra[key] = raw
+			continue
+		}
+		field := rv.Field(f.index)
+		if err = json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("error decoding %s: %v", key, err)
+		}
+		x.known[key] = raw
+		if x.canonical[key], err = json.Marshal(field.Interface()); err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// marshalObject encodes the struct pointed to by v as a json object. Members
+// are written in the order they were decoded in, followed by new members.
+// Members whose value did not change are written with their original json.
+func marshalObject(v interface{}, x *Extras) ([]byte, error) {
+	rv := reflect.ValueOf(v).Elem()
+	fields := objectFields(rv.Type())
+	byName := make(map[string]objectField, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	written := make(map[string]bool, len(x.keys)+len(fields))
+	write := func(key string, value []byte) {
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+		written[key] = true
+	}
+
+	for _, key := range x.keys {
+		if written[key] {
+			continue
+		}
+		f, ok := byName[key]
+		if !ok {
+			if raw, ok := x.extra[key]; ok {
+				write(key, raw)
+			}
+			continue
+		}
+		value, err := json.Marshal(rv.Field(f.index).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if canonical, ok := x.canonical[key]; ok && bytes.Equal(canonical, value) {
+			value = x.known[key]
+		}
+		write(key, value)
+	}
+
+	for _, f := range fields {
+		if written[f.name] {
+			continue
+		}
+		field := rv.Field(f.index)
+		if (x.decoded || f.omitEmpty) && isEmptyValue(field) {
+			continue
+		}
+		value, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if derived, ok := x.derived[f.name]; ok && bytes.Equal(derived, value) {
+			continue
+		}
+		write(f.name, value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
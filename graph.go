@@ -0,0 +1,144 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// topologyGraph is the foundation topology rendered by DOT and Mermaid
+type topologyGraph struct {
+	networks []graphNode
+	zones    []graphZone
+	products []graphNode
+	edges    [][2]string
+}
+
+type graphNode struct {
+	id    string
+	label string
+}
+
+type graphZone struct {
+	graphNode
+	partitions []graphNode
+}
+
+// DOT renders the products, their networks and the active partitions of their
+// jobs with instance counts, grouped into availability zone clusters, as a
+// Graphviz digraph
+func (is *InstallationSettings) DOT() string {
+	g := is.topologyGraph()
+	var buf bytes.Buffer
+	buf.WriteString("digraph foundation {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, network := range g.networks {
+		fmt.Fprintf(&buf, "  %s [label=%s, shape=ellipse];\n", network.id, dotQuote(network.label))
+	}
+	for _, product := range g.products {
+		fmt.Fprintf(&buf, "  %s [label=%s, shape=folder];\n", product.id, dotQuote(product.label))
+	}
+	for _, zone := range g.zones {
+		fmt.Fprintf(&buf, "  subgraph cluster_%s {\n    label=%s;\n", zone.id, dotQuote(zone.label))
+		for _, partition := range zone.partitions {
+			fmt.Fprintf(&buf, "    %s [label=%s];\n", partition.id, dotQuote(partition.label))
+		}
+		buf.WriteString("  }\n")
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(&buf, "  %s -> %s;\n", edge[0], edge[1])
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid renders the same graph as DOT as a Mermaid flowchart
+func (is *InstallationSettings) Mermaid() string {
+	g := is.topologyGraph()
+	var buf bytes.Buffer
+	buf.WriteString("graph LR\n")
+	for _, network := range g.networks {
+		fmt.Fprintf(&buf, "  %s([%s])\n", network.id, mermaidQuote(network.label))
+	}
+	for _, product := range g.products {
+		fmt.Fprintf(&buf, "  %s[/%s/]\n", product.id, mermaidQuote(product.label))
+	}
+	for _, zone := range g.zones {
+		fmt.Fprintf(&buf, "  subgraph %s[%s]\n", zone.id, mermaidQuote(zone.label))
+		for _, partition := range zone.partitions {
+			fmt.Fprintf(&buf, "    %s[%s]\n", partition.id, mermaidQuote(partition.label))
+		}
+		buf.WriteString("  end\n")
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(&buf, "  %s --> %s\n", edge[0], edge[1])
+	}
+	return buf.String()
+}
+
+// topologyGraph builds the graph from the deployments of all products
+func (is *InstallationSettings) topologyGraph() *topologyGraph {
+	g := &topologyGraph{}
+	networks := make(map[string]string)
+	for i, network := range is.Infrastructure.Networks {
+		id := fmt.Sprintf("network_%d", i)
+		networks[network.GUID] = id
+		g.networks = append(g.networks, graphNode{id: id, label: network.Name + "\n" + network.Subnet})
+	}
+	zones := make(map[string]int)
+	for _, az := range is.Infrastructure.AvailabilityZones {
+		zones[az.GUID] = len(g.zones)
+		g.zones = append(g.zones, graphZone{graphNode: graphNode{id: fmt.Sprintf("az_%d", len(g.zones)), label: azLabel(az)}})
+	}
+
+	for i := range is.Products {
+		product := &is.Products[i]
+		productID := fmt.Sprintf("product_%d", i)
+		g.products = append(g.products, graphNode{id: productID, label: strings.TrimSpace(productName(product) + " " + product.ProductVersion)})
+		if network := is.productNetwork(product); network != nil {
+			g.edges = append(g.edges, [2]string{networks[network.GUID], productID})
+		}
+
+		deployment := NewDeployment(is, product.Name)
+		for j, job := range deployment.JobNames() {
+			if job == compilationJob {
+				continue
+			}
+			for k, partition := range deployment.Jobs[job] {
+				zone, ok := zones[partition.AvailabilityZoneReference]
+				if !ok {
+					zone = len(g.zones)
+					zones[partition.AvailabilityZoneReference] = zone
+					g.zones = append(g.zones, graphZone{graphNode: graphNode{id: fmt.Sprintf("az_%d", zone), label: partition.AvailabilityZoneReference}})
+				}
+				id := fmt.Sprintf("job_%d_%d_%d", i, j, k)
+				g.zones[zone].partitions = append(g.zones[zone].partitions, graphNode{id: id, label: fmt.Sprintf("%s\nx%d", job, partition.InstanceCount)})
+				g.edges = append(g.edges, [2]string{productID, id})
+			}
+		}
+	}
+	return g
+}
+
+func azLabel(az AvailabilityZone) string {
+	var placement []string
+	for _, s := range []string{az.Cluster, az.ResourcePool} {
+		if s != "" {
+			placement = append(placement, s)
+		}
+	}
+	if len(placement) == 0 {
+		return az.Name
+	}
+	return fmt.Sprintf("%s (%s)", az.Name, strings.Join(placement, "/"))
+}
+
+func dotQuote(label string) string {
+	label = strings.Replace(label, `\`, `\\`, -1)
+	label = strings.Replace(label, `"`, `\"`, -1)
+	return `"` + strings.Replace(label, "\n", `\n`, -1) + `"`
+}
+
+func mermaidQuote(label string) string {
+	label = strings.Replace(label, `"`, "#quot;", -1)
+	return `"` + strings.Replace(label, "\n", "<br/>", -1) + `"`
+}
@@ -0,0 +1,43 @@
+package opsmanclient_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Topology graphs", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	Describe("DOT", func() {
+		It("renders products, networks and partitions grouped by availability zone", func() {
+			dot := is.DOT()
+			Expect(dot).To(HavePrefix("digraph foundation {\n"))
+			Expect(dot).To(ContainSubstring(`network_0 [label="PCF Deployment Network\n192.168.200.0/23", shape=ellipse];`))
+			Expect(dot).To(ContainSubstring(`product_1 [label="cf 1.6.17-build.10", shape=folder];`))
+			Expect(dot).To(ContainSubstring("subgraph cluster_az_1 {\n    label=\"PCF-Capacity-02"))
+			Expect(dot).To(MatchRegexp(`(?s)subgraph cluster_az_1 \{.*label="diego_cell\\nx1".*\}`))
+			Expect(dot).To(ContainSubstring("network_0 -> product_1;"))
+			Expect(dot).NotTo(ContainSubstring("compilation"))
+			Expect(dot).NotTo(ContainSubstring("dea"))
+		})
+	})
+
+	Describe("Mermaid", func() {
+		It("renders the same graph as a flowchart", func() {
+			mermaid := is.Mermaid()
+			Expect(mermaid).To(HavePrefix("graph LR\n"))
+			Expect(mermaid).To(ContainSubstring(`network_0(["PCF Deployment Network<br/>192.168.200.0/23"])`))
+			Expect(mermaid).To(ContainSubstring(`product_0[/"p-bosh`))
+			Expect(mermaid).To(ContainSubstring(`subgraph az_0["PCF-Capacity-01`))
+			Expect(strings.Count(mermaid, "subgraph")).To(Equal(strings.Count(mermaid, "  end\n")))
+			Expect(strings.Count(mermaid, " --> ")).To(Equal(strings.Count(is.DOT(), " -> ")))
+		})
+	})
+})
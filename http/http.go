@@ -64,28 +64,58 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 }
 
 func (c *Client) Get(url string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := c.newRequest("GET", url, "", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.username != "" && c.password != "" {
-		req.SetBasicAuth(c.username, c.password)
+	return c.Do(req)
+}
+
+func (c *Client) Post(url string, bodyType string, body io.Reader) (resp *http.Response, err error) {
+	req, err := c.newRequest("POST", url, bodyType, body)
+	if err != nil {
+		return nil, err
 	}
 
 	return c.Do(req)
 }
 
-func (c *Client) Post(url string, bodyType string, body io.Reader) (resp *http.Response, err error) {
-	req, err := http.NewRequest("POST", url, body)
+func (c *Client) Put(url string, bodyType string, body io.Reader) (resp *http.Response, err error) {
+	req, err := c.newRequest("PUT", url, bodyType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+func (c *Client) Delete(url string) (resp *http.Response, err error) {
+	req, err := c.newRequest("DELETE", url, "", nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", bodyType)
 
 	return c.Do(req)
 }
 
+func (c *Client) newRequest(method, url, bodyType string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if bodyType != "" {
+		req.Header.Set("Content-Type", bodyType)
+	}
+
+	if c.username != "" && c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	return req, nil
+}
+
 type noFollowRedirect struct{}
 
 func (noFollowRedirect) Error() string {
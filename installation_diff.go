@@ -0,0 +1,304 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of change reported by Diff and ConfigureProduct
+const (
+	ProductAdded          = "product_added"
+	ProductRemoved        = "product_removed"
+	VersionChanged        = "version_changed"
+	StemcellChanged       = "stemcell_changed"
+	JobAdded              = "job_added"
+	JobRemoved            = "job_removed"
+	InstanceCountChanged  = "instance_count_changed"
+	AvailabilityZoneMoved = "availability_zone_changed"
+	PropertyChanged       = "property_changed"
+	IPChanged             = "ip_changed"
+	NetworkChanged        = "network_changed"
+	VMTypeChanged         = "vm_type_changed"
+	PersistentDiskChanged = "persistent_disk_changed"
+	ErrandChanged         = "errand_changed"
+)
+
+// maskedValue replaces secret values in changes
+const maskedValue = "((secret))"
+
+// Diff compares the products of two installation settings, reporting added and
+// removed products and jobs, version and stemcell changes, instance count and
+// availability zone changes per partition, property value changes, with
+// secrets masked, and IP changes
+func Diff(a, b *InstallationSettings) Changes {
+	var changes Changes
+	for _, before := range a.Products {
+		after := findDiffProduct(b, before)
+		if after == nil {
+			changes = append(changes, Change{Kind: ProductRemoved, Product: productName(&before), From: before.ProductVersion})
+			continue
+		}
+		changes = append(changes, diffProduct(a, b, &before, after)...)
+	}
+	for _, after := range b.Products {
+		if findDiffProduct(a, after) == nil {
+			changes = append(changes, Change{Kind: ProductAdded, Product: productName(&after), To: after.ProductVersion})
+		}
+	}
+	return changes
+}
+
+// String renders the changes as one human readable line per change
+func (changes Changes) String() string {
+	var buf bytes.Buffer
+	for _, change := range changes {
+		buf.WriteString(change.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// JSON renders the changes as a json array
+func (changes Changes) JSON() ([]byte, error) {
+	if changes == nil {
+		changes = Changes{}
+	}
+	return json.Marshal(changes)
+}
+
+// String renders the change as a human readable line
+func (c Change) String() string {
+	location := []string{c.Product}
+	for _, part := range []string{c.Job, c.AvailabilityZone, c.Property} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+	switch c.Kind {
+	case ProductAdded, JobAdded:
+		return fmt.Sprintf("+ %s %s", strings.Join(location, "/"), c.To)
+	case ProductRemoved, JobRemoved:
+		return fmt.Sprintf("- %s %s", strings.Join(location, "/"), c.From)
+	}
+	return fmt.Sprintf("~ %s %s: %s -> %s", strings.Join(location, "/"), strings.Replace(c.Kind, "_", " ", -1), c.From, c.To)
+}
+
+func diffProduct(a, b *InstallationSettings, before, after *Products) []Change {
+	name := productName(before)
+	var changes []Change
+	if before.ProductVersion != after.ProductVersion {
+		changes = append(changes, Change{Kind: VersionChanged, Product: name, From: before.ProductVersion, To: after.ProductVersion})
+	}
+	if before.Stemcell.Version != after.Stemcell.Version {
+		changes = append(changes, Change{Kind: StemcellChanged, Product: name, From: before.Stemcell.Version, To: after.Stemcell.Version})
+	}
+	changes = append(changes, diffProperties(name, "", "", before.Properties, after.Properties)...)
+
+	for i := range before.Jobs {
+		job := &before.Jobs[i]
+		other := findDiffJob(after, job)
+		if other == nil {
+			changes = append(changes, Change{Kind: JobRemoved, Product: name, Job: jobName(job)})
+			continue
+		}
+		changes = append(changes, diffPartitions(name, a, b, before, after, job, other)...)
+		changes = append(changes, diffProperties(name, jobName(job), "", job.Properties, other.Properties)...)
+	}
+	for i := range after.Jobs {
+		if findDiffJob(before, &after.Jobs[i]) == nil {
+			changes = append(changes, Change{Kind: JobAdded, Product: name, Job: jobName(&after.Jobs[i])})
+		}
+	}
+	return changes
+}
+
+func diffPartitions(product string, a, b *InstallationSettings, beforeProduct, afterProduct *Products, before, after *Jobs) []Change {
+	var changes []Change
+	beforeCounts, beforeIPs := partitionsByAZ(a, beforeProduct, before)
+	afterCounts, afterIPs := partitionsByAZ(b, afterProduct, after)
+
+	if from, to := activeAZs(beforeCounts), activeAZs(afterCounts); from != to {
+		changes = append(changes, Change{Kind: AvailabilityZoneMoved, Product: product, Job: jobName(before), From: from, To: to})
+	}
+	for _, az := range unionKeys(intKeys(beforeCounts), intKeys(afterCounts)) {
+		if beforeCounts[az] != afterCounts[az] {
+			changes = append(changes, Change{
+				Kind:             InstanceCountChanged,
+				Product:          product,
+				Job:              jobName(before),
+				AvailabilityZone: az,
+				From:             fmt.Sprint(beforeCounts[az]),
+				To:               fmt.Sprint(afterCounts[az]),
+			})
+		}
+	}
+	for _, az := range unionKeys(stringKeys(beforeIPs), stringKeys(afterIPs)) {
+		if beforeIPs[az] != afterIPs[az] {
+			changes = append(changes, Change{Kind: IPChanged, Product: product, Job: jobName(before), AvailabilityZone: az, From: beforeIPs[az], To: afterIPs[az]})
+		}
+	}
+	return changes
+}
+
+// diffProperties compares the values of properties and of the properties of
+// their selected options, which are named <property>.<option>.<name>
+func diffProperties(product, job, prefix string, before, after []Properties) []Change {
+	var changes []Change
+	values := func(properties []Properties) map[string]string {
+		m := make(map[string]string)
+		for i := range properties {
+			b, _ := json.Marshal(properties[i].Value)
+			m[properties[i].Name()] = string(b)
+		}
+		return m
+	}
+	beforeValues, afterValues := values(before), values(after)
+	secrets := make(map[string]bool)
+	for _, properties := range [][]Properties{before, after} {
+		for i := range properties {
+			secrets[properties[i].Name()] = secrets[properties[i].Name()] || hasSecrets(properties[i].Name(), properties[i].Value)
+		}
+	}
+
+	for _, name := range unionKeys(stringKeys(beforeValues), stringKeys(afterValues)) {
+		from, to := beforeValues[name], afterValues[name]
+		if from == to {
+			continue
+		}
+		if secrets[name] {
+			from, to = maskedValue, maskedValue
+		}
+		changes = append(changes, Change{Kind: PropertyChanged, Product: product, Job: job, Property: prefix + name, From: from, To: to})
+	}
+
+	for i := range before {
+		property := &before[i]
+		other, ok := findProperty(after, property.Name())
+		if !ok {
+			continue
+		}
+		for _, option := range selectedOptions(property, other) {
+			changes = append(changes, diffProperties(product, job, prefix+property.Name()+"."+option+".",
+				optionProperties(property, option), optionProperties(other, option))...)
+		}
+	}
+	return changes
+}
+
+// selectedOptions returns the options selected by either value of a selector property
+func selectedOptions(before, after *Properties) []string {
+	var selected []string
+	for _, p := range []*Properties{before, after} {
+		option, ok := p.Value.(string)
+		if !ok || len(p.Options) == 0 || (len(selected) > 0 && selected[0] == option) {
+			continue
+		}
+		selected = append(selected, option)
+	}
+	return selected
+}
+
+func optionProperties(p *Properties, option string) []Properties {
+	for i := range p.Options {
+		if p.Options[i].Identifier == option {
+			return p.Options[i].Properties
+		}
+	}
+	return nil
+}
+
+// partitionsByAZ returns the instance counts and the IPs of a job by
+// availability zone name
+func partitionsByAZ(is *InstallationSettings, product *Products, job *Jobs) (map[string]int, map[string]string) {
+	counts := make(map[string]int)
+	ips := make(map[string]string)
+	for _, partition := range job.Partition {
+		az := partition.AvailabilityZoneReference
+		if zone := is.Infrastructure.findAvailabilityZone(az); zone != nil {
+			az = zone.Name
+		}
+		counts[az] += partition.InstanceCount
+		if addresses := product.IPS[job.GUID+"-partition-"+partition.AvailabilityZoneReference]; len(addresses) > 0 {
+			sorted := append([]string(nil), addresses...)
+			sort.Strings(sorted)
+			ips[az] = strings.Join(sorted, ",")
+		}
+	}
+	return counts, ips
+}
+
+func activeAZs(counts map[string]int) string {
+	var azs []string
+	for az, count := range counts {
+		if count > 0 {
+			azs = append(azs, az)
+		}
+	}
+	sort.Strings(azs)
+	return strings.Join(azs, ",")
+}
+
+func findDiffProduct(is *InstallationSettings, product Products) *Products {
+	for _, key := range []string{product.Identifier, product.GUID} {
+		if key == "" {
+			continue
+		}
+		if p, err := is.FindProduct(key); err == nil {
+			return p
+		}
+	}
+	return nil
+}
+
+func findDiffJob(product *Products, job *Jobs) *Jobs {
+	if j, err := product.FindJob(jobName(job)); err == nil {
+		return j
+	}
+	return nil
+}
+
+func productName(product *Products) string {
+	if product.Identifier != "" {
+		return product.Identifier
+	}
+	return product.Name
+}
+
+func jobName(job *Jobs) string {
+	if job.Identifier != "" {
+		return job.Identifier
+	}
+	return job.InstallationName
+}
+
+func unionKeys(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var union []string
+	for _, key := range append(append([]string(nil), a...), b...) {
+		if !seen[key] {
+			seen[key] = true
+			union = append(union, key)
+		}
+	}
+	sort.Strings(union)
+	return union
+}
+
+func intKeys(m map[string]int) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func stringKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
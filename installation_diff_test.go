@@ -0,0 +1,108 @@
+package opsmanclient_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Diffing installation settings", func() {
+	var before, after *InstallationSettings
+
+	BeforeEach(func() {
+		before = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+		after = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("reports no changes for identical settings", func() {
+		Expect(Diff(before, after)).To(BeEmpty())
+	})
+
+	It("reports version and stemcell changes", func() {
+		after.Products[1].ProductVersion = "1.7.0"
+		after.Products[1].Stemcell.Version = "3232.1"
+		Expect(Diff(before, after)).To(Equal(Changes{
+			{Kind: VersionChanged, Product: "cf", From: "1.6.17-build.10", To: "1.7.0"},
+			{Kind: StemcellChanged, Product: "cf", From: "3146.9", To: "3232.1"},
+		}))
+	})
+
+	It("reports added and removed products", func() {
+		after.Products = after.Products[1:]
+		Expect(Diff(before, after)).To(Equal(Changes{{Kind: ProductRemoved, Product: "p-bosh", From: before.Products[0].ProductVersion}}))
+		Expect(Diff(after, before)).To(Equal(Changes{{Kind: ProductAdded, Product: "p-bosh", To: before.Products[0].ProductVersion}}))
+	})
+
+	It("reports instance count and availability zone changes per partition", func() {
+		Expect(after.SetAvailabilityZones("cf", "router", "PCF-Capacity-01", "PCF-Capacity-02")).To(Succeed())
+		Expect(after.SetInstanceCount("cf", "router", 2)).To(Succeed())
+		changes := Diff(before, after)
+		Expect(changes).To(ContainElement(Change{Kind: AvailabilityZoneMoved, Product: "cf", Job: "router", From: "PCF-Capacity-01", To: "PCF-Capacity-01,PCF-Capacity-02"}))
+		Expect(changes).To(ContainElement(Change{Kind: InstanceCountChanged, Product: "cf", Job: "router", AvailabilityZone: "PCF-Capacity-02", From: "0", To: "1"}))
+	})
+
+	It("masks secret property values", func() {
+		Expect(after.SetProperty("cf", "", "smtp_auth_mechanism", "login")).To(Succeed())
+		Expect(after.SetProperty("cf", "router", "vm_credentials", map[string]string{"identity": "vcap", "password": "rotated"})).To(Succeed())
+		changes := Diff(before, after)
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Property).To(Equal("smtp_auth_mechanism"))
+		Expect(changes[0].To).To(Equal(`"login"`))
+		Expect(changes[1]).To(Equal(Change{Kind: PropertyChanged, Product: "cf", Job: "router", Property: "vm_credentials", From: "((secret))", To: "((secret))"}))
+	})
+
+	It("masks collections with secret members", func() {
+		accounts := func(password string) interface{} {
+			return []interface{}{
+				[]interface{}{
+					map[string]interface{}{"identifier": "name", "value": "backup"},
+					map[string]interface{}{"identifier": "password", "value": password},
+				},
+			}
+		}
+		before.Products[1].Properties = append(before.Products[1].Properties, Properties{Identifier: "service_accounts", Value: accounts("old-password")})
+		after.Products[1].Properties = append(after.Products[1].Properties, Properties{Identifier: "service_accounts", Value: accounts("new-password")})
+		changes := Diff(before, after)
+		Expect(changes).To(Equal(Changes{{Kind: PropertyChanged, Product: "cf", Property: "service_accounts", From: "((secret))", To: "((secret))"}}))
+		Expect(changes.String()).NotTo(ContainSubstring("new-password"))
+	})
+
+	It("reports changes of the properties of selected options", func() {
+		Expect(after.SetProperty("cf", "", "system_database", "external")).To(Succeed())
+		cf, _ := after.FindProduct("cf")
+		database, _ := cf.FindProperty("system_database")
+		selector, err := database.AsSelector()
+		Expect(err).NotTo(HaveOccurred())
+		password, _ := CollectionItem(selector.Properties).Get("password")
+		password.Value = "rotated"
+		host, _ := CollectionItem(selector.Properties).Get("host")
+		host.Value = "mysql.example.com"
+		changes := Diff(before, after)
+		Expect(changes).To(ContainElement(Change{Kind: PropertyChanged, Product: "cf", Property: "system_database", From: `"internal_mysql"`, To: `"external"`}))
+		Expect(changes).To(ContainElement(Change{Kind: PropertyChanged, Product: "cf", Property: "system_database.external.password", From: "((secret))", To: "((secret))"}))
+		Expect(changes.String()).To(ContainSubstring(`~ cf/system_database.external.host property changed: `))
+		Expect(changes.String()).To(ContainSubstring(`-> "mysql.example.com"`))
+	})
+
+	It("reports IP changes", func() {
+		after.Products[1].IPS["router-e6d2273e5dfab999e769-partition-e6f2e103df59e642f38b"] = []string{"192.168.200.99"}
+		changes := Diff(before, after)
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Kind).To(Equal(IPChanged))
+		Expect(changes[0].AvailabilityZone).To(Equal("PCF-Capacity-01"))
+		Expect(changes[0].To).To(Equal("192.168.200.99"))
+	})
+
+	It("renders the changes as text and json", func() {
+		after.Products[1].ProductVersion = "1.7.0"
+		changes := Diff(before, after)
+		Expect(changes.String()).To(Equal("~ cf version changed: 1.6.17-build.10 -> 1.7.0\n"))
+		b, err := changes.JSON()
+		Expect(err).NotTo(HaveOccurred())
+		var decoded []map[string]string
+		Expect(json.Unmarshal(b, &decoded)).To(Succeed())
+		Expect(decoded[0]).To(HaveKeyWithValue("kind", "version_changed"))
+	})
+})
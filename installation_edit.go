@@ -0,0 +1,374 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	nhttp "net/http"
+	"sort"
+	"strings"
+
+	ghttp "github.com/pivotalservices/gtils/http"
+)
+
+// FindProduct returns the product with the given identifier, installation name or GUID
+func (is *InstallationSettings) FindProduct(product string) (*Products, error) {
+	for i := range is.Products {
+		p := &is.Products[i]
+		if p.Identifier == product || p.Name == product || p.GUID == product {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("product %s not found", product)
+}
+
+// FindJob returns the job of the product with the given identifier, installation name or GUID
+func (p *Products) FindJob(job string) (*Jobs, error) {
+	for i := range p.Jobs {
+		j := &p.Jobs[i]
+		if j.Identifier == job || j.InstallationName == job || j.GUID == job {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("job %s not found in product %s", job, p.Name)
+}
+
+// FindProperty returns the product level property with the given identifier
+func (p *Products) FindProperty(identifier string) (*Properties, error) {
+	if property, ok := findProperty(p.Properties, identifier); ok {
+		return property, nil
+	}
+	return nil, fmt.Errorf("property %s not found in product %s", identifier, p.Name)
+}
+
+// FindProperty returns the property of the job with the given identifier
+func (j *Jobs) FindProperty(identifier string) (*Properties, error) {
+	if property, ok := findProperty(j.Properties, identifier); ok {
+		return property, nil
+	}
+	return nil, fmt.Errorf("property %s not found in job %s", identifier, j.InstallationName)
+}
+
+func findProperty(properties []Properties, identifier string) (*Properties, bool) {
+	for i := range properties {
+		if properties[i].Name() == identifier {
+			return &properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetProperty changes the value of an existing property. Product level
+// properties are changed when job is empty.
+func (is *InstallationSettings) SetProperty(product, job, identifier string, value interface{}) error {
+	p, err := is.FindProduct(product)
+	if err != nil {
+		return err
+	}
+	properties := p.Properties
+	if job != "" {
+		j, err := p.FindJob(job)
+		if err != nil {
+			return err
+		}
+		properties = j.Properties
+	}
+
+	if _, err = json.Marshal(value); err != nil {
+		return fmt.Errorf("property %s: %v", identifier, err)
+	}
+	if property, ok := findProperty(properties, identifier); ok {
+		property.Value = value
+		return nil
+	}
+	return fmt.Errorf("property %s not found in %s", identifier, strings.TrimSuffix(product+"/"+job, "/"))
+}
+
+// SetInstanceCount changes the number of instances of a job, spreading them
+// over the availability zones of its partitions
+func (is *InstallationSettings) SetInstanceCount(product, job string, count int) error {
+	if count < 0 {
+		return fmt.Errorf("instance count of %s cannot be negative", job)
+	}
+	p, j, err := is.findJob(product, job)
+	if err != nil {
+		return err
+	}
+	if j.derivedPartitions() {
+		if !j.setInstances(count) {
+			return fmt.Errorf("job %s has no instance count", job)
+		}
+		p.derivePartitions(j)
+		return nil
+	}
+
+	var azs []string
+	for _, partition := range j.Partition {
+		azs = append(azs, partition.AvailabilityZoneReference)
+	}
+	if len(azs) == 0 {
+		return fmt.Errorf("job %s has no partitions", job)
+	}
+	if !j.setInstances(count) {
+		return fmt.Errorf("job %s has no instance count", job)
+	}
+	j.spreadPartitions(count, azs)
+	return nil
+}
+
+// SetAvailabilityZones places the instances of a job in the availability
+// zones with the given names. From schema version 1.7 on the availability zones
+// are set per product, so this places the instances of all its jobs.
+func (is *InstallationSettings) SetAvailabilityZones(product, job string, azNames ...string) error {
+	if len(azNames) == 0 {
+		return fmt.Errorf("job %s needs at least one availability zone", job)
+	}
+	p, j, err := is.findJob(product, job)
+	if err != nil {
+		return err
+	}
+
+	azs := make([]string, len(azNames))
+	for i, name := range azNames {
+		az := is.Infrastructure.findAvailabilityZone(name)
+		if az == nil {
+			return fmt.Errorf("availability zone %s not found", name)
+		}
+		azs[i] = az.GUID
+	}
+	if j.derivedPartitions() {
+		p.AvailabilityZoneReferences = azs
+		for k := range p.Jobs {
+			if p.Jobs[k].derivedPartitions() {
+				p.derivePartitions(&p.Jobs[k])
+			}
+		}
+		return nil
+	}
+	j.spreadPartitions(j.placedInstances(), azs)
+	return nil
+}
+
+// SetResource changes a resource (ram, cpu, ephemeral_disk, persistent_disk) of a job
+func (is *InstallationSettings) SetResource(product, job, identifier string, value int) error {
+	p, err := is.FindProduct(product)
+	if err != nil {
+		return err
+	}
+	j, err := p.FindJob(job)
+	if err != nil {
+		return err
+	}
+	for i := range j.Resources {
+		if j.Resources[i].Identifier == identifier {
+			j.Resources[i].Value = value
+			return nil
+		}
+	}
+	return fmt.Errorf("resource %s not found in %s/%s", identifier, product, job)
+}
+
+func (is *InstallationSettings) findJob(product, job string) (*Products, *Jobs, error) {
+	p, err := is.FindProduct(product)
+	if err != nil {
+		return nil, nil, err
+	}
+	j, err := p.FindJob(job)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, j, nil
+}
+
+func (i *Infrastructure) findAvailabilityZone(name string) *AvailabilityZone {
+	for k := range i.AvailabilityZones {
+		if i.AvailabilityZones[k].Name == name || i.AvailabilityZones[k].GUID == name {
+			return &i.AvailabilityZones[k]
+		}
+	}
+	return nil
+}
+
+// setInstances changes the instance count of the job and reports whether the
+// job has one
+func (j *Jobs) setInstances(count int) bool {
+	for i := range j.Instances {
+		if j.Instances[i].Identifier == "instances" {
+			j.Instances[i].Value = count
+			return true
+		}
+	}
+	return false
+}
+
+// placedInstances returns the instance count of the job, or the number of
+// instances of its partitions when it has none
+func (j *Jobs) placedInstances() int {
+	for _, instances := range j.Instances {
+		if instances.Identifier == "instances" {
+			return instances.Value
+		}
+	}
+	count := 0
+	for _, partition := range j.Partition {
+		count += partition.InstanceCount
+	}
+	return count
+}
+
+// spreadPartitions rebuilds the partitions of the job with count instances
+// over the given availability zones, keeping the members of partitions that
+// remain
+func (j *Jobs) spreadPartitions(count int, azs []string) {
+	existing := make(map[string]Partition)
+	for _, partition := range j.Partition {
+		existing[partition.AvailabilityZoneReference] = partition
+	}
+
+	partitions := spreadInstances(j, count, azs)
+	for i, spread := range partitions {
+		if partition, ok := existing[spread.AvailabilityZoneReference]; ok {
+			partition.InstanceCount = spread.InstanceCount
+			partitions[i] = partition
+		}
+	}
+	j.Partition = partitions
+}
+
+// UploadInstallationSettings compares the installation settings with the ones
+// of Ops Manager and returns the differences. Unless dryRun is set the
+// installation settings are then uploaded, which requires api version 2.0.
+func (c *OpsManAPI) UploadInstallationSettings(installation *InstallationSettings, dryRun bool) ([]string, error) {
+	current, err := c.GetInstallationSettings()
+	if err != nil {
+		return nil, err
+	}
+	diff, err := diffDocuments(current, installation)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return diff, nil
+	}
+
+	version, err := c.GetAPIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if err = ValidateAPIVersion(version); err != nil {
+		return nil, fmt.Errorf("uploading installation settings is not supported: %v", err)
+	}
+
+	var body bytes.Buffer
+	if err = installation.Encode(&body); err != nil {
+		return nil, err
+	}
+	conn := ghttp.ConnAuth{
+		Url:      fmt.Sprintf("%s/api/installation_settings", c.opsmanURL),
+		Username: c.opsmanUsername,
+		Password: c.opsmanPassword,
+	}
+	creds := map[string]string{
+		"password":   c.opsmanPassword,
+		"passphrase": c.opsmanPassphrase,
+	}
+	resp, err := c.AssetsUploader(conn, "installation[file]", "installation.json", int64(body.Len()), &body, creds)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading installation settings, %v", err)
+	}
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, unexpectedStatusErr(resp, nhttp.StatusOK)
+	}
+	return diff, nil
+}
+
+// diffDocuments lists the values that differ between the json encodings of a
+// and b, one line per value: "- path: value", "+ path: value" or
+// "~ path: old -> new". Credentials, passwords and keys are masked.
+func diffDocuments(a, b interface{}) ([]string, error) {
+	before, err := flattenDocument(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flattenDocument(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for path := range before {
+		paths = append(paths, path)
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var diff []string
+	for _, path := range paths {
+		was, inBefore := before[path]
+		is, inAfter := after[path]
+		switch {
+		case !inAfter:
+			diff = append(diff, fmt.Sprintf("- %s: %s", path, was))
+		case !inBefore:
+			diff = append(diff, fmt.Sprintf("+ %s: %s", path, is))
+		case was.json != is.json:
+			diff = append(diff, fmt.Sprintf("~ %s: %s -> %s", path, was, is))
+		}
+	}
+	return diff, nil
+}
+
+// flatValue is the json of a value of a flattened document
+type flatValue struct {
+	json   string
+	secret bool
+}
+
+func (v flatValue) String() string {
+	if v.secret {
+		return maskedValue
+	}
+	return v.json
+}
+
+func flattenDocument(v interface{}) (map[string]flatValue, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]flatValue)
+	flattenValue("", doc, false, values)
+	return values, nil
+}
+
+// flattenValue records the leaf values of v by path. Values of members with a
+// secret name and the values of secret properties are marked secret.
+func flattenValue(path string, v interface{}, secret bool, values map[string]flatValue) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		identifier, _ := value["identifier"].(string)
+		for key, member := range value {
+			memberSecret := secret || isSecretName(key)
+			if key == "value" && identifier != "" {
+				memberSecret = memberSecret || isSecret(identifier, member)
+			}
+			flattenValue(joinPath(path, key), member, memberSecret, values)
+		}
+	case []interface{}:
+		for i, element := range value {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), element, secret, values)
+		}
+	default:
+		b, _ := json.Marshal(value)
+		values[path] = flatValue{json: string(b), secret: secret}
+	}
+}
@@ -0,0 +1,162 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	nhttp "net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ghttp "github.com/pivotalservices/gtils/http"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Editing installation settings", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	Describe("setting a property", func() {
+		It("changes a job property", func() {
+			err := is.SetProperty("cf", "router", "vm_credentials", map[string]string{"identity": "vcap", "password": "rotated"})
+			Expect(err).NotTo(HaveOccurred())
+			matches, _ := is.Lookup("products[cf].jobs[router].properties[vm_credentials].value.password")
+			Expect(matches[0].Value).To(Equal("rotated"))
+		})
+
+		It("changes a product property", func() {
+			Expect(is.SetProperty("cf", "", "smtp_auth_mechanism", "login")).To(Succeed())
+			cf, _ := is.FindProduct("cf")
+			property, err := cf.FindProperty("smtp_auth_mechanism")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(property.Value).To(Equal("login"))
+		})
+
+		It("fails for unknown properties", func() {
+			err := is.SetProperty("cf", "router", "enable_tls", true)
+			Expect(err).To(MatchError("property enable_tls not found in cf/router"))
+
+			router, _ := is.Products[1].FindJob("router")
+			_, err = router.FindProperty("enable_tls")
+			Expect(err).To(MatchError("property enable_tls not found in job router"))
+		})
+	})
+
+	Describe("setting the instance count", func() {
+		It("spreads the instances over the job partitions", func() {
+			Expect(is.SetInstanceCount("cf", "diego_cell", 5)).To(Succeed())
+			job, _ := is.Products[1].FindJob("diego_cell")
+			Expect(job.Instances[0].Value).To(Equal(5))
+			Expect(job.Partition[0].InstanceCount).To(Equal(3))
+			Expect(job.Partition[1].InstanceCount).To(Equal(2))
+		})
+
+		It("fails for jobs without an instance count", func() {
+			job, _ := is.Products[1].FindJob("diego_cell")
+			job.Instances = []Instances{{Identifier: "max_in_flight", Value: 1}}
+			err := is.SetInstanceCount("cf", "diego_cell", 5)
+			Expect(err).To(MatchError("job diego_cell has no instance count"))
+			Expect(job.Partition[0].InstanceCount + job.Partition[1].InstanceCount).To(Equal(3))
+		})
+	})
+
+	Describe("setting the availability zones", func() {
+		It("moves the instances to the named availability zones", func() {
+			Expect(is.SetAvailabilityZones("cf", "diego_cell", "PCF-Capacity-02")).To(Succeed())
+			job, _ := is.Products[1].FindJob("diego_cell")
+			Expect(job.Partition).To(HaveLen(1))
+			Expect(job.Partition[0].AvailabilityZoneReference).To(Equal("0c1b8e8e9268e6f253d6"))
+			Expect(job.Partition[0].InstanceCount).To(Equal(3))
+		})
+
+		It("fails for unknown availability zones", func() {
+			err := is.SetAvailabilityZones("cf", "diego_cell", "PCF-Capacity-03")
+			Expect(err).To(MatchError("availability zone PCF-Capacity-03 not found"))
+		})
+	})
+
+	Context("when the schema version is 1.7", func() {
+		BeforeEach(func() {
+			var err error
+			is, err = DecodeInstallationSettings(strings.NewReader(fixture("installation_settings_1.7.json")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		encode := func() string {
+			var buf bytes.Buffer
+			Expect(is.Encode(&buf)).To(Succeed())
+			return buf.String()
+		}
+
+		It("changes the instance count and spreads the instances again", func() {
+			Expect(is.SetInstanceCount("cf", "diego_cell", 5)).To(Succeed())
+			job, _ := is.Products[0].FindJob("diego_cell")
+			Expect(job.Partition[0].InstanceCount).To(Equal(3))
+			Expect(job.Partition[1].InstanceCount).To(Equal(2))
+			Expect(encode()).To(ContainSubstring(`"instances":[{"identifier":"instances","value":5}]`))
+			Expect(encode()).NotTo(ContainSubstring(`"partitions"`))
+		})
+
+		It("moves the product to the named availability zones", func() {
+			Expect(is.SetAvailabilityZones("cf", "diego_cell", "AZ-02")).To(Succeed())
+			job, _ := is.Products[0].FindJob("diego_cell")
+			Expect(job.Partition).To(HaveLen(1))
+			Expect(job.Partition[0].AvailabilityZoneReference).To(Equal("708192a3b4c5d6e7f809"))
+			Expect(job.Partition[0].InstanceCount).To(Equal(3))
+			Expect(encode()).To(ContainSubstring(`"availability_zone_references":["708192a3b4c5d6e7f809"]`))
+			Expect(encode()).NotTo(ContainSubstring(`"partitions"`))
+		})
+
+		It("changes the instance count of products without availability zones", func() {
+			Expect(is.SetInstanceCount("p-redis", "dedicated-node", 3)).To(Succeed())
+			redis, _ := is.FindProduct("p-redis")
+			Expect(redis.Jobs[0].Instances[0].Value).To(Equal(3))
+			Expect(redis.Jobs[0].Partition).To(BeEmpty())
+		})
+	})
+
+	Describe("uploading", func() {
+		var uploaded *bytes.Buffer
+
+		JustBeforeEach(func() {
+			uploaded = nil
+			opsman.InitializeAPIVersionTest(Version{Version: "2.0"}, false)
+			opsman.InitializeInstallationSettingsTest(fixture("installation_settings.json"))
+			c.AssetsUploader = func(conn ghttp.ConnAuth, paramName, filename string, fileSize int64, fileRef io.Reader, params map[string]string) (*nhttp.Response, error) {
+				Expect(paramName).To(Equal("installation[file]"))
+				uploaded = new(bytes.Buffer)
+				io.Copy(uploaded, fileRef)
+				return &nhttp.Response{StatusCode: nhttp.StatusOK, Body: ioutil.NopCloser(new(bytes.Buffer))}, nil
+			}
+			is.Infrastructure.IaaSConfig.VCenterPassword = "rotated"
+		})
+
+		It("only shows the differences in a dry run", func() {
+			diff, err := c.UploadInstallationSettings(is, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(Equal([]string{"~ infrastructure.iaas_configuration.vcenter_password: ((secret)) -> ((secret))"}))
+			Expect(uploaded).To(BeNil())
+		})
+
+		It("masks changed credentials in a dry run", func() {
+			Expect(is.SetProperty("cf", "router", "vm_credentials", map[string]string{"identity": "operator", "password": "rotated"})).To(Succeed())
+			diff, err := c.UploadInstallationSettings(is, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(ContainElement(MatchRegexp(`^~ products\[1\]\.jobs\[\d+\]\.properties\[\d+\]\.value\.identity: \(\(secret\)\) -> \(\(secret\)\)$`)))
+			for _, line := range diff {
+				Expect(line).NotTo(ContainSubstring("rotated"))
+				Expect(line).NotTo(ContainSubstring("operator"))
+			}
+		})
+
+		It("uploads the installation settings", func() {
+			_, err := c.UploadInstallationSettings(is, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uploaded.String()).To(ContainSubstring(`"vcenter_password":"rotated"`))
+		})
+	})
+})
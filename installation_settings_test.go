@@ -0,0 +1,90 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("InstallationSettings", func() {
+	var (
+		settingsJSON string
+		compacted    bytes.Buffer
+		is           *InstallationSettings
+	)
+
+	BeforeEach(func() {
+		settingsJSON = fixture("installation_settings.json")
+	})
+
+	JustBeforeEach(func() {
+		compacted.Reset()
+		Expect(json.Compact(&compacted, []byte(settingsJSON))).To(Succeed())
+		is = NewInstallationSettingsJSON(settingsJSON)
+	})
+
+	encode := func() string {
+		var out bytes.Buffer
+		Expect(is.Encode(&out)).To(Succeed())
+		return strings.TrimSuffix(out.String(), "\n")
+	}
+
+	It("models the whole document", func() {
+		Expect(is.GUID).To(Equal("28bbfe277aad70481c75"))
+		Expect(is.InstallationSchemaVersion).To(Equal("1.6"))
+		Expect(is.Infrastructure.Networks[0].Subnet).To(Equal("192.168.200.0/23"))
+		Expect(is.Infrastructure.AvailabilityZones).To(HaveLen(2))
+		Expect(is.Infrastructure.IaaSConfig.VCenterPassword).To(Equal("password"))
+		Expect(is.Products[1].Stemcell.Version).To(Equal("3146.9"))
+		Expect(is.Products[1].AvailabilityZoneReferences).To(HaveLen(2))
+		Expect(is.Products[1].Jobs[0].Resources[0].Identifier).To(Equal("ram"))
+		Expect(is.Products[1].Jobs[0].Resources[0].Value).To(Equal(1024))
+	})
+
+	It("encodes an unmodified document byte for byte", func() {
+		Expect(encode()).To(Equal(compacted.String()))
+	})
+
+	It("only changes the modified values", func() {
+		is.Infrastructure.IaaSConfig.VCenterPassword = "rotated"
+		expected := strings.Replace(compacted.String(), `"vcenter_password":"password"`, `"vcenter_password":"rotated"`, 1)
+		Expect(encode()).To(Equal(expected))
+	})
+
+	Context("when the document has members the model does not know", func() {
+		BeforeEach(func() {
+			settingsJSON = strings.Replace(settingsJSON, `"cluster": "Cluster-01",`, `"cluster": "Cluster-01", "host_group": {"name": "hg-01"},`, 1)
+		})
+
+		It("retains them in place", func() {
+			raw, ok := is.Infrastructure.AvailabilityZones[0].Extra("host_group")
+			Expect(ok).To(BeTrue())
+			Expect(string(raw)).To(MatchJSON(`{"name": "hg-01"}`))
+			Expect(is.Infrastructure.AvailabilityZones[0].ExtraKeys()).To(Equal([]string{"host_group"}))
+			Expect(encode()).To(Equal(compacted.String()))
+		})
+
+		It("does not share them with copies", func() {
+			zone := is.Infrastructure.AvailabilityZones[0]
+			zone.SetExtra("host_group", []byte(`{"name":"hg-02"}`))
+			zone.SetExtra("datastore", []byte(`"ds-01"`))
+			zone.DeleteExtra("host_group")
+			zone.SetExtra("datastore", []byte(`"ds-02"`))
+			raw, _ := is.Infrastructure.AvailabilityZones[0].Extra("host_group")
+			Expect(string(raw)).To(MatchJSON(`{"name": "hg-01"}`))
+			Expect(is.Infrastructure.AvailabilityZones[0].ExtraKeys()).To(Equal([]string{"host_group"}))
+			Expect(zone.ExtraKeys()).To(Equal([]string{"datastore"}))
+			Expect(encode()).To(Equal(compacted.String()))
+		})
+	})
+
+	It("encodes values built in code with all their members", func() {
+		b, err := json.Marshal(Partition{JobReference: "router-guid", InstanceCount: 0})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(MatchJSON(`{"job_reference":"router-guid","installation_name":"","instance_count":0,"availability_zone_reference":""}`))
+	})
+})
@@ -0,0 +1,141 @@
+package opsmanclient
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+var ansibleGroupPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+type (
+	ansibleGroup struct {
+		Hosts    map[string]ansibleHost   `yaml:"hosts,omitempty"`
+		Children map[string]*ansibleGroup `yaml:"children,omitempty"`
+	}
+
+	ansibleHost struct {
+		Product          string `yaml:"product"`
+		Job              string `yaml:"job"`
+		Index            int    `yaml:"index"`
+		AvailabilityZone string `yaml:"availability_zone"`
+		BOSHInstance     string `yaml:"bosh_instance"`
+	}
+)
+
+// BOSHInstanceGroups maps the active partitions of every job to the BOSH
+// deployment and instance group running them, in which Ops Manager names the
+// instance group after the partition
+func (d *Deployment) BOSHInstanceGroups() ([]BOSHInstanceGroup, error) {
+	var groups []BOSHInstanceGroup
+	for _, job := range d.JobNames() {
+		instances, err := d.Instances(job)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			if len(groups) == 0 || groups[len(groups)-1].InstanceGroup != instance.Partition {
+				groups = append(groups, BOSHInstanceGroup{
+					Deployment:       d.Release,
+					Job:              job,
+					InstanceGroup:    instance.Partition,
+					AvailabilityZone: instance.AvailabilityZone,
+				})
+			}
+			group := &groups[len(groups)-1]
+			group.Instances++
+			if instance.IP != "" {
+				group.IPs = append(group.IPs, instance.IP)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// AnsibleInventory renders the job instances with an IP address as an Ansible
+// YAML inventory, with a group per product holding a group per job, and a
+// group per availability zone. Hosts carry the BOSH instance they are, as
+// instance group/index.
+func (d *Deployment) AnsibleInventory() ([]byte, error) {
+	product := &ansibleGroup{Children: make(map[string]*ansibleGroup)}
+	all := &ansibleGroup{Children: map[string]*ansibleGroup{ansibleGroupName(d.ProductType): product}}
+
+	err := d.eachInstance(func(instance JobInstance, boshIndex int) {
+		if instance.IP == "" {
+			return
+		}
+		host := ansibleHost{
+			Product:          d.ProductType,
+			Job:              instance.Job,
+			Index:            instance.Index,
+			AvailabilityZone: instance.AvailabilityZone,
+			BOSHInstance:     fmt.Sprintf("%s/%d", instance.Partition, boshIndex),
+		}
+		for _, group := range []*ansibleGroup{
+			product.child(ansibleGroupName(instance.Job)),
+			all.child(ansibleGroupName("az_" + instance.AvailabilityZone)),
+		} {
+			if group.Hosts == nil {
+				group.Hosts = make(map[string]ansibleHost)
+			}
+			group.Hosts[instance.IP] = host
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(map[string]*ansibleGroup{"all": all})
+}
+
+// WriteCSV writes the job instances as csv records of product, job, index,
+// availability zone and IP address
+func (d *Deployment) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"product", "job", "index", "availability_zone", "ip"})
+	err := d.eachInstance(func(instance JobInstance, _ int) {
+		out.Write([]string{d.ProductType, instance.Job, strconv.Itoa(instance.Index), instance.AvailabilityZone, instance.IP})
+	})
+	if err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// eachInstance calls fn with every job instance of the deployment and its
+// index in its BOSH instance group
+func (d *Deployment) eachInstance(fn func(instance JobInstance, boshIndex int)) error {
+	for _, job := range d.JobNames() {
+		instances, err := d.Instances(job)
+		if err != nil {
+			return err
+		}
+		indexes := make(map[string]int)
+		for _, instance := range instances {
+			fn(instance, indexes[instance.Partition])
+			indexes[instance.Partition]++
+		}
+	}
+	return nil
+}
+
+func (g *ansibleGroup) child(name string) *ansibleGroup {
+	if g.Children == nil {
+		g.Children = make(map[string]*ansibleGroup)
+	}
+	child, ok := g.Children[name]
+	if !ok {
+		child = &ansibleGroup{}
+		g.Children[name] = child
+	}
+	return child
+}
+
+// ansibleGroupName turns a name into a valid Ansible group name
+func ansibleGroupName(name string) string {
+	return ansibleGroupPattern.ReplaceAllString(name, "_")
+}
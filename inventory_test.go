@@ -0,0 +1,71 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+	"encoding/csv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Inventories", func() {
+	var deployment *Deployment
+
+	BeforeEach(func() {
+		deployment = NewDeployment(NewInstallationSettingsJSON(fixture("installation_settings.json")), "cf-6455120728b109a1086c")
+	})
+
+	It("maps jobs to BOSH instance groups", func() {
+		groups, err := deployment.BOSHInstanceGroups()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(ContainElement(BOSHInstanceGroup{
+			Deployment:       "cf-6455120728b109a1086c",
+			Job:              "diego_cell",
+			InstanceGroup:    "diego_cell-partition-e6f2e103df59e642f38b",
+			AvailabilityZone: "PCF-Capacity-01",
+			Instances:        2,
+			IPs:              []string{"192.168.200.30", "192.168.200.31"},
+		}))
+		Expect(groups).To(ContainElement(BOSHInstanceGroup{
+			Deployment:       "cf-6455120728b109a1086c",
+			Job:              "diego_cell",
+			InstanceGroup:    "diego_cell-partition-0c1b8e8e9268e6f253d6",
+			AvailabilityZone: "PCF-Capacity-02",
+			Instances:        1,
+			IPs:              []string{"192.168.200.32"},
+		}))
+	})
+
+	It("renders an Ansible inventory with groups per job and availability zone", func() {
+		b, err := deployment.AnsibleInventory()
+		Expect(err).NotTo(HaveOccurred())
+
+		type group struct {
+			Hosts    map[string]map[string]interface{} `yaml:"hosts"`
+			Children map[string]group                  `yaml:"children"`
+		}
+		var inventory struct {
+			All group `yaml:"all"`
+		}
+		Expect(yaml.Unmarshal(b, &inventory)).To(Succeed())
+		Expect(inventory.All.Children).To(HaveKey("az_PCF_Capacity_02"))
+		Expect(inventory.All.Children["az_PCF_Capacity_02"].Hosts).To(HaveLen(3))
+
+		cells := inventory.All.Children["cf"].Children["diego_cell"].Hosts
+		Expect(cells).To(HaveLen(3))
+		Expect(cells["192.168.200.32"]).To(HaveKeyWithValue("bosh_instance", "diego_cell-partition-0c1b8e8e9268e6f253d6/0"))
+		Expect(cells["192.168.200.32"]).To(HaveKeyWithValue("index", 2))
+	})
+
+	It("writes the instances as csv", func() {
+		var buf bytes.Buffer
+		Expect(deployment.WriteCSV(&buf)).To(Succeed())
+		records, err := csv.NewReader(&buf).ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records[0]).To(Equal([]string{"product", "job", "index", "availability_zone", "ip"}))
+		Expect(records).To(ContainElement([]string{"cf", "ccdb", "0", "PCF-Capacity-01", "192.168.200.21"}))
+		Expect(records).To(ContainElement([]string{"cf", "diego_cell", "2", "PCF-Capacity-02", "192.168.200.32"}))
+	})
+})
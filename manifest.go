@@ -0,0 +1,77 @@
+package opsmanclient
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Manifest is a generated BOSH manifest
+type Manifest map[string]interface{}
+
+// GetStagedManifest returns the BOSH manifest Ops Manager generates for the
+// staged product with the given GUID
+func (c *OpsManAPI) GetStagedManifest(productGUID string) (Manifest, error) {
+	var body []byte
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/staged/products/%s/manifest", productGUID), nil, &body); err != nil {
+		return nil, err
+	}
+
+	var resp map[string]interface{}
+	if err := yaml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing staged manifest of %s, %v", productGUID, err)
+	}
+	manifest, ok := normalizeYAML(resp["manifest"]).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no staged manifest found for %s", productGUID)
+	}
+	return Manifest(manifest), nil
+}
+
+// GetDeployedManifest returns the BOSH manifest of the last deployment of the
+// product with the given GUID
+func (c *OpsManAPI) GetDeployedManifest(productGUID string) (Manifest, error) {
+	var body []byte
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/deployed/products/%s/manifest", productGUID), nil, &body); err != nil {
+		return nil, err
+	}
+
+	var resp interface{}
+	if err := yaml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing deployed manifest of %s, %v", productGUID, err)
+	}
+	manifest, ok := normalizeYAML(resp).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no deployed manifest found for %s", productGUID)
+	}
+	return Manifest(manifest), nil
+}
+
+// YAML renders the manifest with sorted keys, so two renderings can be diffed
+func (m Manifest) YAML() ([]byte, error) {
+	return yaml.Marshal(map[string]interface{}(m))
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values yaml produces
+// into map[string]interface{} so manifests can be used like decoded json
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return m
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeYAML(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+		return value
+	}
+	return v
+}
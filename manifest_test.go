@@ -0,0 +1,55 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Manifest", func() {
+	var (
+		err      error
+		manifest opsmanclient.Manifest
+	)
+
+	JustBeforeEach(func() {
+		opsman.InitializeManifestTest(map[string]string{
+			"cf-guid": `{"manifest": {"name": "cf-guid", "instance_groups": [{"name": "router", "instances": 2}]}}`,
+		}, map[string]string{
+			"cf-guid": "name: cf-guid\ninstance_groups:\n- name: router\n  instances: 1\n",
+		})
+	})
+
+	Describe("staged manifest", func() {
+		JustBeforeEach(func() {
+			manifest, err = c.GetStagedManifest("cf-guid")
+		})
+
+		It("parses the manifest", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest["name"]).To(Equal("cf-guid"))
+			groups := manifest["instance_groups"].([]interface{})
+			Expect(groups[0].(map[string]interface{})["instances"]).To(Equal(2))
+		})
+
+		It("renders the manifest as yaml", func() {
+			out, err := manifest.YAML()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal("instance_groups:\n- instances: 2\n  name: router\nname: cf-guid\n"))
+		})
+	})
+
+	Describe("deployed manifest", func() {
+		It("parses the manifest", func() {
+			manifest, err = c.GetDeployedManifest("cf-guid")
+			Expect(err).NotTo(HaveOccurred())
+			groups := manifest["instance_groups"].([]interface{})
+			Expect(groups[0].(map[string]interface{})["instances"]).To(Equal(1))
+		})
+
+		It("fails for unknown products", func() {
+			_, err = c.GetDeployedManifest("p-mysql-guid")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
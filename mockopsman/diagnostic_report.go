@@ -0,0 +1,25 @@
+package mockopsman
+
+import (
+	"net/http"
+
+	"github.com/pivotalservices/opsmanclient"
+)
+
+func (o *OpsManager) InitializeDiagnosticReportTest(report *opsmanclient.DiagnosticReport) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.DiagnosticReport = report
+}
+
+func (o *OpsManager) getDiagnosticReport(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if o.DiagnosticReport == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	o.writeJSON(w, o.DiagnosticReport)
+}
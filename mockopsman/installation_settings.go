@@ -0,0 +1,21 @@
+package mockopsman
+
+import "net/http"
+
+func (o *OpsManager) InitializeInstallationSettingsTest(settings string) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.InstallationSettings = settings
+}
+
+func (o *OpsManager) getInstallationSettings(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if o.InstallationSettings == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(o.InstallationSettings))
+}
@@ -0,0 +1,37 @@
+package mockopsman
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (o *OpsManager) InitializeManifestTest(staged, deployed map[string]string) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.StagedManifests = staged
+	o.DeployedManifests = deployed
+}
+
+func (o *OpsManager) getStagedManifest(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeManifest(w, o.StagedManifests[mux.Vars(r)["guid"]])
+}
+
+func (o *OpsManager) getDeployedManifest(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeManifest(w, o.DeployedManifests[mux.Vars(r)["guid"]])
+}
+
+func (o *OpsManager) writeManifest(w http.ResponseWriter, manifest string) {
+	if manifest == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(manifest))
+}
@@ -1,10 +1,14 @@
 package mockopsman
 
 import (
+	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"sync"
 
 	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 	"github.com/pivotalservices/opsmanclient"
 )
 
@@ -14,6 +18,40 @@ type OpsManager struct {
 	GetAPIVersionCalls     int
 	stubbedAPIVersionCalls []StubbedAPIVersionCall
 
+	// Certificates
+	CertificateAuthorities      []opsmanclient.CertificateAuthority
+	PendingChanges              []opsmanclient.ProductChange
+	GeneratedCertificateDomains []string
+	RegenerateCertificatesCalls int
+
+	// Diagnostic report
+	DiagnosticReport *opsmanclient.DiagnosticReport
+
+	// Installation settings
+	InstallationSettings string
+
+	// VM and disk types
+	VMTypes   []opsmanclient.VMType
+	DiskTypes []opsmanclient.DiskType
+
+	// UAA
+	UAAToken        string
+	UAAUsers        []opsmanclient.UAAUser
+	UAAClients      []opsmanclient.UAAClient
+	UAAGroupMembers map[string][]string
+
+	// Manifests
+	StagedManifests   map[string]string
+	DeployedManifests map[string]string
+
+	// Staged products
+	StagedProperties       map[string]map[string]opsmanclient.StagedProperty
+	StagedPropertiesUpdate string
+	StagedNetworksAndAZs   map[string]*opsmanclient.NetworksAndAZs
+	StagedResourceConfigs  map[string]*opsmanclient.JobResourceConfig
+	StagedErrands          map[string][]opsmanclient.Errand
+	StagedProductUpdates   int
+
 	// common
 	shouldFail bool
 	FailBody   string
@@ -31,7 +69,49 @@ func New() *OpsManager {
 	om := &OpsManager{Mutex: new(sync.Mutex)}
 	router := mux.NewRouter()
 	router.HandleFunc("/api/api_version", om.getAPIVersion).Methods("GET")
+	router.HandleFunc("/api/v0/certificates/generate", om.generateCertificate).Methods("POST")
+	router.HandleFunc("/api/v0/certificate_authorities", om.listCertificateAuthorities).Methods("GET")
+	router.HandleFunc("/api/v0/certificate_authorities", om.createCertificateAuthority).Methods("POST")
+	router.HandleFunc("/api/v0/certificate_authorities/generate", om.createCertificateAuthority).Methods("POST")
+	router.HandleFunc("/api/v0/certificate_authorities/active/regenerate", om.regenerateCertificates).Methods("POST")
+	router.HandleFunc("/api/v0/certificate_authorities/{guid}/activate", om.activateCertificateAuthority).Methods("POST")
+	router.HandleFunc("/api/v0/certificate_authorities/{guid}", om.deleteCertificateAuthority).Methods("DELETE")
+	router.HandleFunc("/api/v0/staged/pending_changes", om.getPendingChanges).Methods("GET")
+	router.HandleFunc("/api/v0/diagnostic_report", om.getDiagnosticReport).Methods("GET")
+	router.HandleFunc("/api/installation_settings", om.getInstallationSettings).Methods("GET")
+	router.HandleFunc("/api/v0/vm_types", om.listVMTypes).Methods("GET")
+	router.HandleFunc("/api/v0/vm_types", om.replaceVMTypes).Methods("PUT")
+	router.HandleFunc("/api/v0/vm_types", om.deleteVMTypes).Methods("DELETE")
+	router.HandleFunc("/api/v0/disk_types", om.listDiskTypes).Methods("GET")
+	router.HandleFunc("/uaa/Users", om.listUAAUsers).Methods("GET")
+	router.HandleFunc("/uaa/Users", om.createUAAUser).Methods("POST")
+	router.HandleFunc("/uaa/Users/{id}", om.deleteUAAUser).Methods("DELETE")
+	router.HandleFunc("/uaa/Groups", om.listUAAGroups).Methods("GET")
+	router.HandleFunc("/uaa/Groups/{id}/members", om.addUAAGroupMember).Methods("POST")
+	router.HandleFunc("/uaa/oauth/clients", om.listUAAClients).Methods("GET")
+	router.HandleFunc("/uaa/oauth/clients", om.createUAAClient).Methods("POST")
+	router.HandleFunc("/uaa/oauth/clients/{id}", om.getUAAClient).Methods("GET")
+	router.HandleFunc("/uaa/oauth/clients/{id}", om.updateUAAClient).Methods("PUT")
+	router.HandleFunc("/uaa/oauth/clients/{id}", om.deleteUAAClient).Methods("DELETE")
+	router.HandleFunc("/api/v0/staged/products/{guid}/manifest", om.getStagedManifest).Methods("GET")
+	router.HandleFunc("/api/v0/deployed/products/{guid}/manifest", om.getDeployedManifest).Methods("GET")
+	router.HandleFunc("/api/v0/staged/products/{guid}/properties", om.getStagedProperties).Methods("GET")
+	router.HandleFunc("/api/v0/staged/products/{guid}/properties", om.updateStagedProperties).Methods("PUT")
+	router.HandleFunc("/api/v0/staged/products/{guid}/networks_and_azs", om.getNetworksAndAZs).Methods("GET")
+	router.HandleFunc("/api/v0/staged/products/{guid}/networks_and_azs", om.updateNetworksAndAZs).Methods("PUT")
+	router.HandleFunc("/api/v0/staged/products/{guid}/jobs/{job}/resource_config", om.getJobResourceConfig).Methods("GET")
+	router.HandleFunc("/api/v0/staged/products/{guid}/jobs/{job}/resource_config", om.updateJobResourceConfig).Methods("PUT")
+	router.HandleFunc("/api/v0/staged/products/{guid}/errands", om.listErrands).Methods("GET")
+	router.HandleFunc("/api/v0/staged/products/{guid}/errands", om.updateErrands).Methods("PUT")
 	om.Server = httptest.NewServer(router)
 	om.FailBody = "epic fail"
 	return om
 }
+
+func (o *OpsManager) writeJSON(w http.ResponseWriter, v interface{}) {
+	defer GinkgoRecover()
+
+	responseBytes, err := json.Marshal(v)
+	Expect(err).NotTo(HaveOccurred())
+	w.Write(responseBytes)
+}
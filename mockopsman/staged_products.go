@@ -0,0 +1,141 @@
+package mockopsman
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+func (o *OpsManager) InitializeStagedProductTest(productGUID string, properties map[string]opsmanclient.StagedProperty, networksAndAZs *opsmanclient.NetworksAndAZs, resourceConfigs map[string]*opsmanclient.JobResourceConfig, errands []opsmanclient.Errand) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.StagedProperties = map[string]map[string]opsmanclient.StagedProperty{productGUID: properties}
+	o.StagedNetworksAndAZs = map[string]*opsmanclient.NetworksAndAZs{productGUID: networksAndAZs}
+	o.StagedResourceConfigs = resourceConfigs
+	o.StagedErrands = map[string][]opsmanclient.Errand{productGUID: errands}
+	o.StagedPropertiesUpdate = ""
+	o.StagedProductUpdates = 0
+}
+
+func (o *OpsManager) getStagedProperties(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	properties, ok := o.StagedProperties[mux.Vars(r)["guid"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	// the api does not return the values of credentials
+	masked := make(map[string]opsmanclient.StagedProperty, len(properties))
+	for reference, property := range properties {
+		if property.Credential {
+			property.Value = map[string]string{"secret": "***"}
+		}
+		masked[reference] = property
+	}
+	o.writeJSON(w, map[string]interface{}{"properties": masked})
+}
+
+func (o *OpsManager) updateStagedProperties(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	body, err := ioutil.ReadAll(r.Body)
+	Expect(err).NotTo(HaveOccurred())
+	o.StagedPropertiesUpdate = string(body)
+	var req struct {
+		Properties map[string]opsmanclient.StagedProperty `json:"properties"`
+	}
+	Expect(json.Unmarshal(body, &req)).To(Succeed())
+	properties := o.StagedProperties[mux.Vars(r)["guid"]]
+	for reference, update := range req.Properties {
+		property := properties[reference]
+		property.Value = update.Value
+		properties[reference] = property
+	}
+	o.StagedProductUpdates++
+}
+
+func (o *OpsManager) getNetworksAndAZs(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeJSON(w, map[string]interface{}{"networks_and_azs": o.StagedNetworksAndAZs[mux.Vars(r)["guid"]]})
+}
+
+func (o *OpsManager) updateNetworksAndAZs(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	var req struct {
+		NetworksAndAZs *opsmanclient.NetworksAndAZs `json:"networks_and_azs"`
+	}
+	Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+	o.StagedNetworksAndAZs[mux.Vars(r)["guid"]] = req.NetworksAndAZs
+	o.StagedProductUpdates++
+}
+
+func (o *OpsManager) getJobResourceConfig(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	config, ok := o.StagedResourceConfigs[mux.Vars(r)["job"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	o.writeJSON(w, config)
+}
+
+func (o *OpsManager) updateJobResourceConfig(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	var config opsmanclient.JobResourceConfig
+	Expect(json.NewDecoder(r.Body).Decode(&config)).To(Succeed())
+	o.StagedResourceConfigs[mux.Vars(r)["job"]] = &config
+	o.StagedProductUpdates++
+}
+
+func (o *OpsManager) listErrands(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeJSON(w, map[string]interface{}{"errands": o.StagedErrands[mux.Vars(r)["guid"]]})
+}
+
+func (o *OpsManager) updateErrands(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	var req struct {
+		Errands []opsmanclient.Errand `json:"errands"`
+	}
+	Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+	errands := o.StagedErrands[mux.Vars(r)["guid"]]
+	for _, update := range req.Errands {
+		for i := range errands {
+			if errands[i].Name != update.Name {
+				continue
+			}
+			if update.PostDeploy != nil {
+				errands[i].PostDeploy = update.PostDeploy
+			}
+			if update.PreDelete != nil {
+				errands[i].PreDelete = update.PreDelete
+			}
+		}
+	}
+	o.StagedProductUpdates++
+}
@@ -0,0 +1,195 @@
+package mockopsman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+func (o *OpsManager) InitializeUAATest(token string, users []opsmanclient.UAAUser, clients []opsmanclient.UAAClient) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.UAAToken = token
+	o.UAAUsers = users
+	o.UAAClients = clients
+	o.UAAGroupMembers = make(map[string][]string)
+}
+
+func (o *OpsManager) authorizedUAARequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer "+o.UAAToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (o *OpsManager) listUAAUsers(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	o.writeJSON(w, map[string]interface{}{
+		"resources":    o.UAAUsers,
+		"totalResults": len(o.UAAUsers),
+	})
+}
+
+func (o *OpsManager) createUAAUser(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	var user opsmanclient.UAAUser
+	Expect(json.NewDecoder(r.Body).Decode(&user)).To(Succeed())
+	user.ID = fmt.Sprintf("user-id-%d", len(o.UAAUsers)+1)
+	o.UAAUsers = append(o.UAAUsers, user)
+
+	user.Password = ""
+	w.WriteHeader(http.StatusCreated)
+	o.writeJSON(w, user)
+}
+
+func (o *OpsManager) deleteUAAUser(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	for i, user := range o.UAAUsers {
+		if user.ID == id {
+			o.UAAUsers = append(o.UAAUsers[:i], o.UAAUsers[i+1:]...)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (o *OpsManager) listUAAGroups(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	var groups []map[string]string
+	filter := r.URL.Query().Get("filter")
+	if strings.HasPrefix(filter, `displayName eq "opsman.`) {
+		name := strings.TrimSuffix(strings.TrimPrefix(filter, `displayName eq "`), `"`)
+		groups = append(groups, map[string]string{"id": name + "-id", "displayName": name})
+	}
+	o.writeJSON(w, map[string]interface{}{"resources": groups})
+}
+
+func (o *OpsManager) addUAAGroupMember(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	var member map[string]string
+	Expect(json.NewDecoder(r.Body).Decode(&member)).To(Succeed())
+	id := mux.Vars(r)["id"]
+	o.UAAGroupMembers[id] = append(o.UAAGroupMembers[id], member["value"])
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (o *OpsManager) listUAAClients(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	o.writeJSON(w, map[string]interface{}{
+		"resources":    o.UAAClients,
+		"totalResults": len(o.UAAClients),
+	})
+}
+
+func (o *OpsManager) createUAAClient(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	var client opsmanclient.UAAClient
+	Expect(json.NewDecoder(r.Body).Decode(&client)).To(Succeed())
+	o.UAAClients = append(o.UAAClients, client)
+
+	client.ClientSecret = ""
+	w.WriteHeader(http.StatusCreated)
+	o.writeJSON(w, client)
+}
+
+func (o *OpsManager) getUAAClient(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	for _, client := range o.UAAClients {
+		if client.ClientID == id {
+			o.writeJSON(w, client)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (o *OpsManager) updateUAAClient(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	var update opsmanclient.UAAClient
+	Expect(json.NewDecoder(r.Body).Decode(&update)).To(Succeed())
+	id := mux.Vars(r)["id"]
+	for i, client := range o.UAAClients {
+		if client.ClientID == id {
+			o.UAAClients[i] = update
+			o.writeJSON(w, update)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (o *OpsManager) deleteUAAClient(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	if !o.authorizedUAARequest(w, r) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	for i, client := range o.UAAClients {
+		if client.ClientID == id {
+			o.UAAClients = append(o.UAAClients[:i], o.UAAClients[i+1:]...)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
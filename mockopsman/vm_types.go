@@ -0,0 +1,57 @@
+package mockopsman
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+func (o *OpsManager) InitializeVMTypesTest(vmTypes []opsmanclient.VMType, diskTypes []opsmanclient.DiskType) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.VMTypes = vmTypes
+	o.DiskTypes = diskTypes
+}
+
+func (o *OpsManager) listVMTypes(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeJSON(w, map[string][]opsmanclient.VMType{"vm_types": o.VMTypes})
+}
+
+func (o *OpsManager) replaceVMTypes(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	defer GinkgoRecover()
+	var req struct {
+		VMTypes []opsmanclient.VMType `json:"vm_types"`
+	}
+	Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+	o.VMTypes = req.VMTypes
+}
+
+func (o *OpsManager) deleteVMTypes(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	var builtin []opsmanclient.VMType
+	for _, vmType := range o.VMTypes {
+		if vmType.Builtin {
+			builtin = append(builtin, vmType)
+		}
+	}
+	o.VMTypes = builtin
+}
+
+func (o *OpsManager) listDiskTypes(w http.ResponseWriter, r *http.Request) {
+	o.Mutex.Lock()
+	defer o.Mutex.Unlock()
+
+	o.writeJSON(w, map[string][]opsmanclient.DiskType{"disk_types": o.DiskTypes})
+}
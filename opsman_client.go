@@ -29,8 +29,13 @@ type OpsManAPI struct {
 	logger            *logging.Logger
 	AssetsUploader    httpUploader
 	SettingsRequestor httpRequestor
+	UAATokenGetter    uaaTokenGetter
+	// ExportRedaction, when set, redacts the secrets of exported installation settings
+	ExportRedaction *Redaction
 }
 
+type uaaTokenGetter func(uaaURL, username, password, clientID, clientSecret string) (string, error)
+
 type httpUploader func(conn ghttp.ConnAuth, paramName, filename string, fileSize int64, fileRef io.Reader, params map[string]string) (*nhttp.Response, error)
 
 type httpRequestor interface {
@@ -45,6 +50,15 @@ type HTTPClient interface {
 	Post(url string, bodyType string, body io.Reader) (resp *nhttp.Response, err error)
 }
 
+// HTTPRequestClient is implemented by HTTP clients that can also make the PUT
+// and DELETE calls of the Ops Man API. HTTPClient implementations without them
+// keep working for everything else. UAA calls only need a Do method.
+type HTTPRequestClient interface {
+	HTTPClient
+	Put(url string, bodyType string, body io.Reader) (resp *nhttp.Response, err error)
+	Delete(url string) (resp *nhttp.Response, err error)
+}
+
 // New creates a Client for calling Ops Man API
 func New(opsmanURL, opsmanUsername, opsmanPassword, opsmanPassphrase string, isS3 bool) *OpsManAPI {
 	logger := setupLogger()
@@ -62,6 +76,7 @@ func New(opsmanURL, opsmanUsername, opsmanPassword, opsmanPassphrase string, isS
 		opsmanPassphrase:  opsmanPassphrase,
 		AssetsUploader:    httpUploader(getUploader(isS3)),
 		SettingsRequestor: ghttp.NewHttpGateway(),
+		UAATokenGetter:    uaa.GetToken,
 	}
 }
 
@@ -75,25 +90,29 @@ func (c *OpsManAPI) GetCFDeployment(installation *InstallationSettings, products
 	return NewDeployment(installation, cfRelease), nil
 }
 
-// GetInstallationSettings retrieves installation settings for cf deployment
-func (c *OpsManAPI) GetInstallationSettings() (*InstallationSettings, error) {
-	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/api/installation_settings", c.opsmanURL))
+// GetDeployment returns the deployment of the product of the given type, such
+// as cf, p-bosh, p-mysql or any other tile
+func (c *OpsManAPI) GetDeployment(productType string) (*Deployment, error) {
+	installation, err := c.GetInstallationSettings()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
+	product, err := installation.FindProduct(productType)
 	if err != nil {
 		return nil, err
 	}
+	return NewDeployment(installation, product.Name), nil
+}
 
-	res := bytes.NewBufferString(string(body))
-	decoder := json.NewDecoder(res)
-	var installation *InstallationSettings
-	err = decoder.Decode(&installation)
+// GetInstallationSettings retrieves installation settings for cf deployment
+func (c *OpsManAPI) GetInstallationSettings() (*InstallationSettings, error) {
+	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/api/installation_settings", c.opsmanURL))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-	return installation, err
+	return DecodeInstallationSettings(resp.Body)
 }
 
 // GetInstallationSettingsRaw returns installation settings in raw format
@@ -121,7 +140,7 @@ func (c *OpsManAPI) GetInstallationSettingsRaw() ([]byte, error) {
 }
 
 // GetInstallationSettingsBuffered retrieves all the installation settings from OpsMan
-// and returns them in a buffered reader
+// and returns them in a buffered reader, redacted when ExportRedaction is set
 func (c *OpsManAPI) GetInstallationSettingsBuffered() (io.Reader, error) {
 
 	var bytesBuffer = new(bytes.Buffer)
@@ -131,6 +150,9 @@ func (c *OpsManAPI) GetInstallationSettingsBuffered() (io.Reader, error) {
 	if err := c.saveHTTPResponse(url, bytesBuffer); err != nil {
 		return nil, err
 	}
+	if c.ExportRedaction != nil {
+		return c.redactExport(bytesBuffer)
+	}
 	return bytesBuffer, nil
 }
 
@@ -171,7 +193,18 @@ func (c *OpsManAPI) SaveDeployments(e command.Executer, bw io.WriteCloser) error
 	return e.Execute(bw, cmd)
 }
 
+// SaveInstallation exports the installation settings and the installation asset
+// collection. When ExportRedaction is set only the redacted installation settings
+// are written, as the asset collection holds the secrets encrypted.
 func (c *OpsManAPI) SaveInstallation(backupWriter io.WriteCloser) error {
+	if c.ExportRedaction != nil {
+		settings, err := c.GetInstallationSettingsBuffered()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(backupWriter, settings)
+		return err
+	}
 	err := c.exportFile("%s/api/installation_settings", "installation.json", backupWriter)
 	if err != nil {
 		return err
@@ -179,6 +212,22 @@ func (c *OpsManAPI) SaveInstallation(backupWriter io.WriteCloser) error {
 	return c.exportFile("%s/api/installation_asset_collection", "installation.zip", backupWriter)
 }
 
+func (c *OpsManAPI) redactExport(settings io.Reader) (io.Reader, error) {
+	installation, err := DecodeInstallationSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	redacted, err := Redact(installation, *c.ExportRedaction)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := redacted.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
 func (c *OpsManAPI) exportFile(urlFormat string, filename string, backupWriter io.WriteCloser) error {
 	url := fmt.Sprintf(urlFormat, c.opsmanURL)
 
@@ -234,7 +283,7 @@ func (c *OpsManAPI) oauthHTTPGet(urlString string) (*nhttp.Response, error) {
 	var err error
 	c.logger.Debug("aquiring your token from: ", uaaURL, urlString)
 
-	if token, err = uaa.GetToken("https://"+uaaURL.Host+"/uaa", opsManagerUsername, opsManagerPassword, clientID, clientSecret); err == nil {
+	if token, err = c.UAATokenGetter("https://"+uaaURL.Host+"/uaa", opsManagerUsername, opsManagerPassword, clientID, clientSecret); err == nil {
 		c.logger.Debug("your token", token, "https://"+uaaURL.Host+"/uaa")
 		requestor := c.SettingsRequestor
 		response, err = requestor.Get(ghttp.HttpRequestEntity{
@@ -297,6 +346,72 @@ func (c *OpsManAPI) removeExistingDeploymentFiles(e command.Executer) error {
 	return e.Execute(&w, command)
 }
 
+// apiRequest sends reqBody as JSON to the given Ops Manager API path and decodes
+// the JSON response into respBody, when respBody is not nil. A *[]byte respBody
+// receives the raw response body.
+func (c *OpsManAPI) apiRequest(method, path string, reqBody, respBody interface{}) error {
+	url := fmt.Sprintf("%s/api/%s", c.opsmanURL, path)
+	c.logger.Debugf("%s %s", method, url)
+
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	var resp *nhttp.Response
+	var err error
+	client, extended := c.HTTPClient.(HTTPRequestClient)
+	switch {
+	case method == "GET":
+		resp, err = c.HTTPClient.Get(url)
+	case method == "POST":
+		resp, err = c.HTTPClient.Post(url, "application/json", body)
+	case (method == "PUT" || method == "DELETE") && !extended:
+		return fmt.Errorf("the http client does not support %s requests", method)
+	case method == "PUT":
+		resp, err = client.Put(url, "application/json", body)
+	case method == "DELETE":
+		resp, err = client.Delete(url)
+	default:
+		return fmt.Errorf("unsupported http method %s", method)
+	}
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return unexpectedStatusErr(resp, nhttp.StatusOK)
+	}
+	defer resp.Body.Close()
+
+	if respBody == nil {
+		return nil
+	}
+	if raw, ok := respBody.(*[]byte); ok {
+		*raw, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+		return fmt.Errorf("error unmarshalling %s json response: %s", path, err)
+	}
+	return nil
+}
+
+// StatusError is returned when Ops Manager responds with an unexpected HTTP status
+type StatusError struct {
+	ExpectedStatus int
+	StatusCode     int
+	Body           string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("expected status %d, was %d. Response Body: %s", e.ExpectedStatus, e.StatusCode, e.Body)
+}
+
 func unexpectedStatusErr(response *nhttp.Response, expectedStatus int) error {
 	defer response.Body.Close()
 	body, err := ioutil.ReadAll(response.Body)
@@ -307,7 +422,12 @@ func unexpectedStatusErr(response *nhttp.Response, expectedStatus int) error {
 		bodyStr = "COULDN'T READ RESPONSE BODY"
 	}
 
-	return fmt.Errorf("expected status %d, was %d. Response Body: %s", expectedStatus, response.StatusCode, bodyStr)
+	return &StatusError{ExpectedStatus: expectedStatus, StatusCode: response.StatusCode, Body: bodyStr}
+}
+
+func isStatus(err error, statusCode int) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == statusCode
 }
 
 // gets the product GUID for a given product type
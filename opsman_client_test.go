@@ -1,9 +1,15 @@
 package opsmanclient_test
 
 import (
+	"io/ioutil"
+	nhttp "net/http"
+	"net/http/httptest"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pivotalservices/opsmanclient"
+	"github.com/pivotalservices/opsmanclient/http"
 )
 
 var _ = Describe("OpsmanClient", func() {
@@ -35,4 +41,35 @@ var _ = Describe("OpsmanClient", func() {
 			})
 		})
 	})
+
+	Describe("http client", func() {
+		var (
+			server   *httptest.Server
+			username string
+			password string
+			body     string
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(nhttp.HandlerFunc(func(w nhttp.ResponseWriter, r *nhttp.Request) {
+				username, password, _ = r.BasicAuth()
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("sends basic auth with posts like with gets", func() {
+			client := http.New(http.Config{Username: "admin", Password: "secret"})
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(nhttp.StatusOK))
+			Expect(username).To(Equal("admin"))
+			Expect(password).To(Equal("secret"))
+			Expect(body).To(Equal(`{}`))
+		})
+	})
 })
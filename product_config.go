@@ -0,0 +1,430 @@
+package opsmanclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// GetStagedProperties returns the properties of a staged product by reference,
+// e.g. .properties.smtp_address or .router.static_ips
+func (c *OpsManAPI) GetStagedProperties(productGUID string) (map[string]StagedProperty, error) {
+	var resp struct {
+		Properties map[string]StagedProperty `json:"properties"`
+	}
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/staged/products/%s/properties", productGUID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Properties, nil
+}
+
+// UpdateStagedProperties sets the values of properties of a staged product
+func (c *OpsManAPI) UpdateStagedProperties(productGUID string, values map[string]interface{}) error {
+	properties := make(map[string]map[string]interface{}, len(values))
+	for reference, value := range values {
+		properties[reference] = map[string]interface{}{"value": value}
+	}
+	req := map[string]interface{}{"properties": properties}
+	return c.apiRequest("PUT", fmt.Sprintf("v0/staged/products/%s/properties", productGUID), req, nil)
+}
+
+// GetNetworksAndAZs returns the network and availability zones of a staged product
+func (c *OpsManAPI) GetNetworksAndAZs(productGUID string) (*NetworksAndAZs, error) {
+	var resp struct {
+		NetworksAndAZs NetworksAndAZs `json:"networks_and_azs"`
+	}
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/staged/products/%s/networks_and_azs", productGUID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.NetworksAndAZs, nil
+}
+
+// UpdateNetworksAndAZs sets the network and availability zones of a staged product
+func (c *OpsManAPI) UpdateNetworksAndAZs(productGUID string, networksAndAZs *NetworksAndAZs) error {
+	req := map[string]*NetworksAndAZs{"networks_and_azs": networksAndAZs}
+	return c.apiRequest("PUT", fmt.Sprintf("v0/staged/products/%s/networks_and_azs", productGUID), req, nil)
+}
+
+// GetJobResourceConfig returns the resource configuration of a job of a staged product
+func (c *OpsManAPI) GetJobResourceConfig(productGUID, jobGUID string) (*JobResourceConfig, error) {
+	var config JobResourceConfig
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/staged/products/%s/jobs/%s/resource_config", productGUID, jobGUID), nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// UpdateJobResourceConfig sets the resource configuration of a job of a staged product
+func (c *OpsManAPI) UpdateJobResourceConfig(productGUID, jobGUID string, config *JobResourceConfig) error {
+	return c.apiRequest("PUT", fmt.Sprintf("v0/staged/products/%s/jobs/%s/resource_config", productGUID, jobGUID), config, nil)
+}
+
+// ListErrands returns the errands of a staged product
+func (c *OpsManAPI) ListErrands(productGUID string) ([]Errand, error) {
+	var resp struct {
+		Errands []Errand `json:"errands"`
+	}
+	if err := c.apiRequest("GET", fmt.Sprintf("v0/staged/products/%s/errands", productGUID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Errands, nil
+}
+
+// UpdateErrands sets the state of errands of a staged product
+func (c *OpsManAPI) UpdateErrands(productGUID string, errands []Errand) error {
+	req := map[string][]Errand{"errands": errands}
+	return c.apiRequest("PUT", fmt.Sprintf("v0/staged/products/%s/errands", productGUID), req, nil)
+}
+
+// ParseProductConfig parses the YAML or JSON desired state of a product:
+//
+//	product: cf
+//	properties:
+//	  .properties.smtp_address: smtp.example.com
+//	network: default
+//	singleton_availability_zone: az1
+//	availability_zones: [az1, az2]
+//	resources:
+//	  router: {instances: 2, instance_type: large, persistent_disk_mb: "10240"}
+//	errands:
+//	  smoke-tests: {post_deploy: false}
+func ParseProductConfig(data []byte) (*ProductConfig, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing product config, %v", err)
+	}
+	b, err := json.Marshal(normalizeYAML(document))
+	if err != nil {
+		return nil, err
+	}
+	var config ProductConfig
+	if err = json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("error reading product config, %v", err)
+	}
+	if config.Product == "" {
+		return nil, fmt.Errorf("product config does not name a product")
+	}
+	return &config, nil
+}
+
+// ConfigureProduct compares the desired state of a product with its staged
+// configuration and returns the changes. Unless planOnly is set only the
+// sections with changes are then updated, so that applying an unchanged config
+// again does nothing. Credentials are compared with the installation settings,
+// as the properties api does not return their values.
+func (c *OpsManAPI) ConfigureProduct(config *ProductConfig, planOnly bool) (Changes, error) {
+	installation, err := c.GetInstallationSettings()
+	if err != nil {
+		return nil, err
+	}
+	product, err := installation.FindProduct(config.Product)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes Changes
+	var apply []func() error
+
+	propertyChanges, properties, err := c.planProperties(config, installation, product)
+	if err != nil {
+		return nil, err
+	}
+	if len(properties) > 0 {
+		changes = append(changes, propertyChanges...)
+		apply = append(apply, func() error { return c.UpdateStagedProperties(product.GUID, properties) })
+	}
+
+	networkChanges, networksAndAZs, err := c.planNetworksAndAZs(config, installation, product)
+	if err != nil {
+		return nil, err
+	}
+	if len(networkChanges) > 0 {
+		changes = append(changes, networkChanges...)
+		apply = append(apply, func() error { return c.UpdateNetworksAndAZs(product.GUID, networksAndAZs) })
+	}
+
+	for _, name := range sortedKeys(config.Resources) {
+		job, err := product.FindJob(name)
+		if err != nil {
+			return nil, err
+		}
+		resourceChanges, resourceConfig, err := c.planJobResources(product, job, config.Resources[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(resourceChanges) > 0 {
+			changes = append(changes, resourceChanges...)
+			jobGUID := job.GUID
+			apply = append(apply, func() error { return c.UpdateJobResourceConfig(product.GUID, jobGUID, resourceConfig) })
+		}
+	}
+
+	errandChanges, errands, err := c.planErrands(config, product)
+	if err != nil {
+		return nil, err
+	}
+	if len(errands) > 0 {
+		changes = append(changes, errandChanges...)
+		apply = append(apply, func() error { return c.UpdateErrands(product.GUID, errands) })
+	}
+
+	if planOnly {
+		return changes, nil
+	}
+	for _, update := range apply {
+		if err := update(); err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+func (c *OpsManAPI) planProperties(config *ProductConfig, installation *InstallationSettings, product *Products) (Changes, map[string]interface{}, error) {
+	if len(config.Properties) == 0 {
+		return nil, nil, nil
+	}
+	staged, err := c.GetStagedProperties(product.GUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes Changes
+	updates := make(map[string]interface{})
+	for _, reference := range sortedKeys(config.Properties) {
+		current, ok := staged[reference]
+		if !ok {
+			return nil, nil, fmt.Errorf("property %s not found in %s", reference, config.Product)
+		}
+		desired := config.Properties[reference]
+		if current.Credential {
+			current.Value, ok = credentialValue(installation, config.Product, reference)
+			if ok && sameCredential(current.Value, desired) {
+				continue
+			}
+		} else if sameValue(current.Value, desired) {
+			continue
+		}
+		from, to := jsonString(current.Value), jsonString(desired)
+		if current.Credential || isSecret(reference, desired) {
+			from, to = maskedValue, maskedValue
+		}
+		job, property := splitPropertyReference(reference)
+		changes = append(changes, Change{Kind: PropertyChanged, Product: config.Product, Job: job, Property: property, From: from, To: to})
+		updates[reference] = desired
+	}
+	return changes, updates, nil
+}
+
+func (c *OpsManAPI) planNetworksAndAZs(config *ProductConfig, installation *InstallationSettings, product *Products) (Changes, *NetworksAndAZs, error) {
+	if config.Network == "" && config.SingletonAvailabilityZone == "" && len(config.AvailabilityZones) == 0 {
+		return nil, nil, nil
+	}
+	for _, az := range append([]string{config.SingletonAvailabilityZone}, config.AvailabilityZones...) {
+		if az != "" && installation.Infrastructure.findAvailabilityZone(az) == nil {
+			return nil, nil, fmt.Errorf("availability zone %s not found", az)
+		}
+	}
+	current, err := c.GetNetworksAndAZs(product.GUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes Changes
+	desired := *current
+	if config.Network != "" && referenceName(current.Network) != config.Network {
+		changes = append(changes, Change{Kind: NetworkChanged, Product: config.Product, From: referenceName(current.Network), To: config.Network})
+		desired.Network = &NamedReference{Name: config.Network}
+	}
+	if config.SingletonAvailabilityZone != "" && referenceName(current.SingletonAvailabilityZone) != config.SingletonAvailabilityZone {
+		changes = append(changes, Change{
+			Kind:     AvailabilityZoneMoved,
+			Product:  config.Product,
+			Property: "singleton_availability_zone",
+			From:     referenceName(current.SingletonAvailabilityZone),
+			To:       config.SingletonAvailabilityZone,
+		})
+		desired.SingletonAvailabilityZone = &NamedReference{Name: config.SingletonAvailabilityZone}
+	}
+	if len(config.AvailabilityZones) > 0 {
+		var from []string
+		for _, az := range current.OtherAvailabilityZones {
+			from = append(from, az.Name)
+		}
+		if strings.Join(from, ",") != strings.Join(config.AvailabilityZones, ",") {
+			changes = append(changes, Change{
+				Kind:     AvailabilityZoneMoved,
+				Product:  config.Product,
+				Property: "availability_zones",
+				From:     strings.Join(from, ","),
+				To:       strings.Join(config.AvailabilityZones, ","),
+			})
+			desired.OtherAvailabilityZones = nil
+			for _, az := range config.AvailabilityZones {
+				desired.OtherAvailabilityZones = append(desired.OtherAvailabilityZones, NamedReference{Name: az})
+			}
+		}
+	}
+	return changes, &desired, nil
+}
+
+func (c *OpsManAPI) planJobResources(product *Products, job *Jobs, settings JobResourceSettings) (Changes, *JobResourceConfig, error) {
+	current, err := c.GetJobResourceConfig(product.GUID, job.GUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes Changes
+	desired := *current
+	name := jobName(job)
+	if settings.Instances != nil && fmt.Sprint(current.Instances) != strconv.Itoa(*settings.Instances) {
+		changes = append(changes, Change{Kind: InstanceCountChanged, Product: productName(product), Job: name, From: fmt.Sprint(current.Instances), To: strconv.Itoa(*settings.Instances)})
+		desired.Instances = *settings.Instances
+	}
+	if settings.InstanceType != "" && current.InstanceType.ID != settings.InstanceType {
+		changes = append(changes, Change{Kind: VMTypeChanged, Product: productName(product), Job: name, From: current.InstanceType.ID, To: settings.InstanceType})
+		desired.InstanceType = InstanceTypeRef{ID: settings.InstanceType}
+	}
+	if settings.PersistentDiskMB != "" {
+		from := ""
+		if current.PersistentDisk != nil {
+			from = current.PersistentDisk.SizeMB
+		}
+		if from != settings.PersistentDiskMB {
+			changes = append(changes, Change{Kind: PersistentDiskChanged, Product: productName(product), Job: name, From: from, To: settings.PersistentDiskMB})
+			desired.PersistentDisk = &PersistentDiskRef{SizeMB: settings.PersistentDiskMB}
+		}
+	}
+	return changes, &desired, nil
+}
+
+func (c *OpsManAPI) planErrands(config *ProductConfig, product *Products) (Changes, []Errand, error) {
+	if len(config.Errands) == 0 {
+		return nil, nil, nil
+	}
+	current, err := c.ListErrands(product.GUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]Errand, len(current))
+	for _, errand := range current {
+		existing[errand.Name] = errand
+	}
+
+	var changes Changes
+	var updates []Errand
+	for _, name := range sortedKeys(config.Errands) {
+		errand, ok := existing[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("errand %s not found in %s", name, config.Product)
+		}
+		settings := config.Errands[name]
+		update := Errand{Name: name}
+		changed := false
+		if settings.PostDeploy != nil && !sameValue(errand.PostDeploy, settings.PostDeploy) {
+			changes = append(changes, Change{Kind: ErrandChanged, Product: config.Product, Job: name, Property: "post_deploy", From: jsonString(errand.PostDeploy), To: jsonString(settings.PostDeploy)})
+			update.PostDeploy, changed = settings.PostDeploy, true
+		}
+		if settings.PreDelete != nil && !sameValue(errand.PreDelete, settings.PreDelete) {
+			changes = append(changes, Change{Kind: ErrandChanged, Product: config.Product, Job: name, Property: "pre_delete", From: jsonString(errand.PreDelete), To: jsonString(settings.PreDelete)})
+			update.PreDelete, changed = settings.PreDelete, true
+		}
+		if changed {
+			updates = append(updates, update)
+		}
+	}
+	return changes, updates, nil
+}
+
+// credentialValue returns the value of a credential property from the
+// installation settings. Properties of options are named
+// <property>.<option>.<name> and are looked up among the properties of the
+// named option.
+func credentialValue(installation *InstallationSettings, product, reference string) (interface{}, bool) {
+	job, identifier := splitPropertyReference(reference)
+	p, err := installation.FindProduct(product)
+	if err != nil {
+		return nil, false
+	}
+	properties := p.Properties
+	if job != "" {
+		j, err := p.FindJob(job)
+		if err != nil {
+			return nil, false
+		}
+		properties = j.Properties
+	}
+	path := strings.Split(identifier, ".")
+	for len(path) >= 3 {
+		property, ok := findProperty(properties, path[0])
+		if !ok {
+			return nil, false
+		}
+		properties, path = optionProperties(property, path[1]), path[2:]
+	}
+	if len(path) != 1 {
+		return nil, false
+	}
+	property, ok := findProperty(properties, path[0])
+	if !ok {
+		return nil, false
+	}
+	return property.Value, true
+}
+
+// splitPropertyReference splits .properties.<name> and .<job>.<name> property
+// references into the job and property name, which keeps the option path of
+// option properties such as system_database.external.password
+func splitPropertyReference(reference string) (string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(reference, "."), ".", 2)
+	if len(parts) != 2 {
+		return "", reference
+	}
+	if parts[0] == "properties" {
+		return "", parts[1]
+	}
+	return parts[0], parts[1]
+}
+
+func referenceName(reference *NamedReference) string {
+	if reference == nil {
+		return ""
+	}
+	return reference.Name
+}
+
+// sameValue compares values by their json encoding
+func sameValue(a, b interface{}) bool {
+	var x, y interface{}
+	if json.Unmarshal([]byte(jsonString(a)), &x) != nil || json.Unmarshal([]byte(jsonString(b)), &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// sameCredential compares the members of a credential the desired value sets,
+// ignoring the ones Ops Manager generates such as salts
+func sameCredential(current, desired interface{}) bool {
+	c, currentIsMap := current.(map[string]interface{})
+	d, desiredIsMap := desired.(map[string]interface{})
+	if !currentIsMap || !desiredIsMap {
+		return sameValue(current, desired)
+	}
+	for key, value := range d {
+		if !sameValue(c[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
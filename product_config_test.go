@@ -0,0 +1,127 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Configuring products", func() {
+	var config *ProductConfig
+
+	BeforeEach(func() {
+		opsman.InitializeInstallationSettingsTest(fixture("installation_settings.json"))
+		opsman.InitializeStagedProductTest("cf-6455120728b109a1086c",
+			map[string]StagedProperty{
+				".properties.smtp_address": {Value: "smtp.example.com", Configurable: true},
+				".router.vm_credentials":   {Credential: true, Configurable: true},
+			},
+			&NetworksAndAZs{
+				Network:                   &NamedReference{Name: "default"},
+				SingletonAvailabilityZone: &NamedReference{Name: "PCF-Capacity-01"},
+				OtherAvailabilityZones:    []NamedReference{{Name: "PCF-Capacity-01"}},
+			},
+			map[string]*JobResourceConfig{
+				"router-e6d2273e5dfab999e769": {Instances: 1, InstanceType: InstanceTypeRef{ID: "automatic"}},
+			},
+			[]Errand{{Name: "smoke-tests", PostDeploy: true}},
+		)
+
+		var err error
+		config, err = ParseProductConfig([]byte(`
+product: cf
+properties:
+  .properties.smtp_address: smtp.sys.example.com
+  .router.vm_credentials: {identity: vcap, password: oG98b7XF88F7823by4dt}
+availability_zones: [PCF-Capacity-01, PCF-Capacity-02]
+resources:
+  router: {instances: 2, instance_type: large}
+errands:
+  smoke-tests: {post_deploy: false}
+`))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("plans the changes without applying them", func() {
+		changes, err := c.ConfigureProduct(config, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Equal(Changes{
+			{Kind: PropertyChanged, Product: "cf", Property: "smtp_address", From: `"smtp.example.com"`, To: `"smtp.sys.example.com"`},
+			{Kind: AvailabilityZoneMoved, Product: "cf", Property: "availability_zones", From: "PCF-Capacity-01", To: "PCF-Capacity-01,PCF-Capacity-02"},
+			{Kind: InstanceCountChanged, Product: "cf", Job: "router", From: "1", To: "2"},
+			{Kind: VMTypeChanged, Product: "cf", Job: "router", From: "automatic", To: "large"},
+			{Kind: ErrandChanged, Product: "cf", Job: "smoke-tests", Property: "post_deploy", From: "true", To: "false"},
+		}))
+		Expect(opsman.StagedProductUpdates).To(Equal(0))
+	})
+
+	It("applies only what changed and is idempotent", func() {
+		_, err := c.ConfigureProduct(config, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opsman.StagedProductUpdates).To(Equal(4))
+		Expect(opsman.StagedProperties["cf-6455120728b109a1086c"][".properties.smtp_address"].Value).To(Equal("smtp.sys.example.com"))
+		Expect(opsman.StagedPropertiesUpdate).To(MatchJSON(`{"properties":{".properties.smtp_address":{"value":"smtp.sys.example.com"}}}`))
+		Expect(opsman.StagedResourceConfigs["router-e6d2273e5dfab999e769"].InstanceType.ID).To(Equal("large"))
+		Expect(opsman.StagedErrands["cf-6455120728b109a1086c"][0].PostDeploy).To(Equal(false))
+
+		changes, err := c.ConfigureProduct(config, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+		Expect(opsman.StagedProductUpdates).To(Equal(4))
+	})
+
+	It("masks changed credentials", func() {
+		config.Properties[".router.vm_credentials"] = map[string]interface{}{"identity": "vcap", "password": "rotated"}
+		changes, err := c.ConfigureProduct(config, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(ContainElement(Change{Kind: PropertyChanged, Product: "cf", Job: "router", Property: "vm_credentials", From: "((secret))", To: "((secret))"}))
+	})
+
+	Context("when a credential belongs to an option", func() {
+		BeforeEach(func() {
+			is := NewInstallationSettingsJSON(fixture("installation_settings.json"))
+			Expect(is.SetProperty("cf", "", "system_database", "external")).To(Succeed())
+			cf, _ := is.FindProduct("cf")
+			database, _ := cf.FindProperty("system_database")
+			selector, err := database.AsSelector()
+			Expect(err).NotTo(HaveOccurred())
+			password, _ := CollectionItem(selector.Properties).Get("password")
+			password.Value = map[string]interface{}{"secret": "D4tab4seP4ss"}
+			var settings bytes.Buffer
+			Expect(is.Encode(&settings)).To(Succeed())
+			opsman.InitializeInstallationSettingsTest(settings.String())
+			opsman.StagedProperties["cf-6455120728b109a1086c"][".properties.system_database.external.password"] = StagedProperty{Credential: true, Configurable: true}
+		})
+
+		It("compares it with the value of the option property", func() {
+			config.Properties[".properties.system_database.external.password"] = map[string]interface{}{"secret": "D4tab4seP4ss"}
+			_, err := c.ConfigureProduct(config, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsman.StagedPropertiesUpdate).NotTo(ContainSubstring("system_database"))
+
+			changes, err := c.ConfigureProduct(config, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+
+		It("masks its changes", func() {
+			config.Properties[".properties.system_database.external.password"] = map[string]interface{}{"secret": "rotated"}
+			changes, err := c.ConfigureProduct(config, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(ContainElement(Change{Kind: PropertyChanged, Product: "cf", Property: "system_database.external.password", From: "((secret))", To: "((secret))"}))
+		})
+	})
+
+	It("fails for unknown properties", func() {
+		config.Properties[".properties.unknown"] = "value"
+		_, err := c.ConfigureProduct(config, true)
+		Expect(err).To(MatchError("property .properties.unknown not found in cf"))
+	})
+
+	It("requires a product", func() {
+		_, err := ParseProductConfig([]byte(`properties: {}`))
+		Expect(err).To(MatchError("product config does not name a product"))
+	})
+})
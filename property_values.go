@@ -0,0 +1,168 @@
+package opsmanclient
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PropertyTypeError is returned when the value of a property does not have
+// the requested type
+type PropertyTypeError struct {
+	Property string
+	Expected string
+	Value    interface{}
+}
+
+func (e *PropertyTypeError) Error() string {
+	return fmt.Sprintf("property %s: expected %s value, got %s", e.Property, e.Expected, jsonTypeName(e.Value))
+}
+
+// Name returns the identifier of the property, or its definition when it has
+// no identifier
+func (p *Properties) Name() string {
+	if p.Identifier != "" {
+		return p.Identifier
+	}
+	return p.Definition
+}
+
+// AsString returns the value of a string property
+func (p *Properties) AsString() (string, error) {
+	if s, ok := p.Value.(string); ok {
+		return s, nil
+	}
+	return "", p.typeError("string")
+}
+
+// AsInt returns the value of an integer property
+func (p *Properties) AsInt() (int, error) {
+	if f, ok := p.Value.(float64); ok && f == math.Trunc(f) {
+		return int(f), nil
+	}
+	if i, ok := p.Value.(int); ok {
+		return i, nil
+	}
+	return 0, p.typeError("integer")
+}
+
+// AsBool returns the value of a boolean property
+func (p *Properties) AsBool() (bool, error) {
+	if b, ok := p.Value.(bool); ok {
+		return b, nil
+	}
+	return false, p.typeError("boolean")
+}
+
+// AsSimpleCredentials returns the value of a simple_credentials property
+func (p *Properties) AsSimpleCredentials() (*SimpleCredentials, error) {
+	m, ok := p.Value.(map[string]interface{})
+	if !ok {
+		return nil, p.typeError("simple_credentials")
+	}
+	identity, ok := m["identity"].(string)
+	if !ok {
+		return nil, p.typeError("simple_credentials")
+	}
+	password, ok := m["password"].(string)
+	if !ok {
+		return nil, p.typeError("simple_credentials")
+	}
+	salt, _ := m["salt"].(string)
+	return &SimpleCredentials{Identity: identity, Password: password, Salt: salt}, nil
+}
+
+// AsRSACert returns the value of a rsa_cert_credentials property
+func (p *Properties) AsRSACert() (*RSACertCredentials, error) {
+	m, ok := p.Value.(map[string]interface{})
+	if !ok {
+		return nil, p.typeError("rsa_cert_credentials")
+	}
+	certPEM, ok := m["cert_pem"].(string)
+	if !ok {
+		return nil, p.typeError("rsa_cert_credentials")
+	}
+	privateKeyPEM, ok := m["private_key_pem"].(string)
+	if !ok {
+		return nil, p.typeError("rsa_cert_credentials")
+	}
+	return &RSACertCredentials{CertPEM: certPEM, PrivateKeyPEM: privateKeyPEM}, nil
+}
+
+// AsCollection returns the items of a collection property. Items are either
+// lists of properties or objects, whose members are turned into properties.
+func (p *Properties) AsCollection() ([]CollectionItem, error) {
+	values, ok := p.Value.([]interface{})
+	if !ok {
+		return nil, p.typeError("collection")
+	}
+
+	items := make([]CollectionItem, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, element := range v {
+				m, ok := element.(map[string]interface{})
+				if !ok {
+					return nil, p.typeError("collection")
+				}
+				property := Properties{Value: m["value"]}
+				property.Identifier, _ = m["identifier"].(string)
+				items[i] = append(items[i], property)
+			}
+		case map[string]interface{}:
+			var keys []string
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				items[i] = append(items[i], Properties{Identifier: key, Value: v[key]})
+			}
+		default:
+			return nil, p.typeError("collection")
+		}
+	}
+	return items, nil
+}
+
+// AsSelector returns the selected option of a selector property
+func (p *Properties) AsSelector() (*Selector, error) {
+	value, ok := p.Value.(string)
+	if !ok || len(p.Options) == 0 {
+		return nil, p.typeError("selector")
+	}
+	for _, option := range p.Options {
+		if option.Identifier == value {
+			return &Selector{Value: value, Properties: option.Properties}, nil
+		}
+	}
+	return nil, fmt.Errorf("property %s: selected option %s is not one of its options", p.Name(), value)
+}
+
+// Get returns the property of the collection item with the given identifier
+func (item CollectionItem) Get(identifier string) (*Properties, bool) {
+	return findProperty(item, identifier)
+}
+
+func (p *Properties) typeError(expected string) error {
+	return &PropertyTypeError{Property: p.Name(), Expected: expected, Value: p.Value}
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, int:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,186 @@
+package opsmanclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Match is a value found by Lookup along with its location in the
+// installation settings, e.g. products[1].jobs[6].properties[0]
+type Match struct {
+	Path  string
+	Value interface{}
+}
+
+type querySegment struct {
+	name     string
+	selector string
+	indexed  bool
+}
+
+// identifyingMembers are the members matched by a selector, in order
+var identifyingMembers = []string{"identifier", "installation_name", "name", "guid"}
+
+// Lookup finds the values of the installation settings matching a path
+// expression. Segments are separated by dots, and an array segment can select
+// its elements by index or by identifier, installation name, name or guid:
+//
+//	products[cf].jobs[router].properties[enable_ssl]
+//	infrastructure.availability_zones[0].name
+//
+// Member names and selectors can use the wildcards of path.Match, so
+// products[*].jobs[diego_*].instances selects the instances of every Diego job.
+func (is *InstallationSettings) Lookup(expr string) ([]Match, error) {
+	segments, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(is)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	matches := []Match{{Value: doc}}
+	for _, segment := range segments {
+		var next []Match
+		for _, m := range matches {
+			found, err := segment.apply(m)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query %q: %v", expr, err)
+			}
+			next = append(next, found...)
+		}
+		matches = next
+	}
+	return matches, nil
+}
+
+func parseQuery(expr string) ([]querySegment, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("invalid query %q: empty expression", expr)
+	}
+
+	parts, ok := splitQuery(expr)
+	if !ok {
+		return nil, fmt.Errorf("invalid query %q: unterminated selector", expr)
+	}
+	var segments []querySegment
+	for _, part := range parts {
+		segment := querySegment{name: part}
+		if open := strings.Index(part, "["); open >= 0 {
+			if strings.Index(part, "]") != len(part)-1 || open == len(part)-2 {
+				return nil, fmt.Errorf("invalid query %q: malformed selector in %s", expr, part)
+			}
+			segment.name = part[:open]
+			segment.selector = part[open+1 : len(part)-1]
+			segment.indexed = true
+		}
+		if segment.name == "" {
+			return nil, fmt.Errorf("invalid query %q: empty member name", expr)
+		}
+		if _, err := path.Match(segment.name, ""); err != nil {
+			return nil, fmt.Errorf("invalid query %q: %v", expr, err)
+		}
+		if _, err := path.Match(segment.selector, ""); err != nil {
+			return nil, fmt.Errorf("invalid query %q: %v", expr, err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// splitQuery splits a path expression at the dots outside of selectors, so
+// that selectors can contain dots, and reports whether every selector is closed
+func splitQuery(expr string) ([]string, bool) {
+	var parts []string
+	start, inSelector := 0, false
+	for i, r := range expr {
+		switch {
+		case r == '[' && !inSelector:
+			inSelector = true
+		case r == ']' && inSelector:
+			inSelector = false
+		case r == '.' && !inSelector:
+			parts = append(parts, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, expr[start:]), !inSelector
+}
+
+func (s querySegment) apply(m Match) ([]Match, error) {
+	object, ok := m.Value.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	for name := range object {
+		if ok, _ := path.Match(s.name, name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var matches []Match
+	for _, name := range names {
+		member := Match{Path: joinPath(m.Path, name), Value: object[name]}
+		if !s.indexed {
+			matches = append(matches, member)
+			continue
+		}
+
+		elements, ok := member.Value.([]interface{})
+		if !ok {
+			continue
+		}
+		if index, err := strconv.Atoi(s.selector); err == nil {
+			if index >= 0 && index < len(elements) {
+				matches = append(matches, Match{Path: fmt.Sprintf("%s[%d]", member.Path, index), Value: elements[index]})
+			}
+			continue
+		}
+		for i, element := range elements {
+			if selects(s.selector, element) {
+				matches = append(matches, Match{Path: fmt.Sprintf("%s[%d]", member.Path, i), Value: element})
+			}
+		}
+	}
+	return matches, nil
+}
+
+func selects(selector string, element interface{}) bool {
+	if selector == "*" {
+		return true
+	}
+	object, ok := element.(map[string]interface{})
+	if !ok {
+		s, ok := element.(string)
+		matched, _ := path.Match(selector, s)
+		return ok && matched
+	}
+	for _, member := range identifyingMembers {
+		if s, ok := object[member].(string); ok {
+			if matched, _ := path.Match(selector, s); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,75 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Lookup", func() {
+	var (
+		is      *InstallationSettings
+		matches []Match
+		err     error
+	)
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("finds a job property of a product", func() {
+		matches, err = is.Lookup("products[cf].jobs[router].properties[vm_credentials].value.identity")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Path).To(Equal("products[1].jobs[5].properties[0].value.identity"))
+		Expect(matches[0].Value).To(Equal("vcap"))
+	})
+
+	It("finds values by index and name", func() {
+		matches, err = is.Lookup("infrastructure.availability_zones[1].name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Value).To(Equal("PCF-Capacity-02"))
+	})
+
+	It("supports wildcards", func() {
+		matches, err = is.Lookup("products[*].jobs[diego_*].partitions[*].instance_count")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(6))
+
+		matches, err = is.Lookup("products[cf].ips.diego_cell-*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(2))
+	})
+
+	It("supports dots in selectors", func() {
+		router, _ := is.Products[1].FindJob("router")
+		router.Properties = append(router.Properties, Properties{Identifier: ".properties.router_backend_max_conn", Value: 500})
+		matches, err = is.Lookup("products[*].jobs[*].properties[.properties.router_backend_max_conn].value")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Value).To(BeEquivalentTo(500))
+
+		matches, err = is.Lookup("products[cf].ips.*[192.168.200.13]")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Path).To(Equal("products[1].ips.consul_server-eb73912b6529fe550cdc-partition-e6f2e103df59e642f38b[0]"))
+	})
+
+	It("returns nothing when nothing matches", func() {
+		matches, err = is.Lookup("products[p-mysql].jobs")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(BeEmpty())
+	})
+
+	It("rejects malformed expressions", func() {
+		_, err = is.Lookup("products[cf.jobs")
+		Expect(err).To(MatchError(`invalid query "products[cf.jobs": unterminated selector`))
+
+		_, err = is.Lookup("products[cf]jobs")
+		Expect(err).To(MatchError(`invalid query "products[cf]jobs": malformed selector in products[cf]jobs`))
+
+		_, err = is.Lookup("products..jobs")
+		Expect(err).To(MatchError(`invalid query "products..jobs": empty member name`))
+	})
+})
@@ -0,0 +1,233 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RedactedPlaceholder replaces secrets when redacting without a salt
+const RedactedPlaceholder = "((redacted))"
+
+// Redaction configures how secrets are replaced in redacted installation settings
+type Redaction struct {
+	// Salt, when set, replaces each secret with a salted hash of its value so
+	// that changed secrets remain visible in redacted copies
+	Salt string
+}
+
+// secretVisitor is called with the path and the value of every secret of
+// installation settings and returns the value to replace it with
+type secretVisitor func(path, secret string) string
+
+// Redact returns a copy of the installation settings with the IaaS credentials
+// and every password, secret and private key of the product properties replaced
+func Redact(is *InstallationSettings, redaction Redaction) (*InstallationSettings, error) {
+	redacted, err := is.copy()
+	if err != nil {
+		return nil, err
+	}
+	redacted.visitSecrets(isSecretName, func(path, secret string) string {
+		return redaction.replace(secret)
+	})
+	return redacted, nil
+}
+
+// copy returns a deep copy of the installation settings
+func (is *InstallationSettings) copy() (*InstallationSettings, error) {
+	var buf bytes.Buffer
+	if err := is.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return DecodeInstallationSettings(&buf)
+}
+
+func (r Redaction) replace(secret string) string {
+	if r.Salt == "" {
+		return RedactedPlaceholder
+	}
+	mac := hmac.New(sha256.New, []byte(r.Salt))
+	mac.Write([]byte(secret))
+	return fmt.Sprintf("((redacted:%s))", hex.EncodeToString(mac.Sum(nil))[:16])
+}
+
+// visitSecrets replaces the non empty IaaS credentials and the values whose
+// member or property name matches secret by the values returned by visit. This
+// includes the members the model does not know about, such as IaaS specific
+// credentials or director database and blobstore passwords. Paths are
+// iaas_configuration/<member>, director_configuration/<member>,
+// infrastructure/<member>, networks/<network>/<member> and
+// availability_zones/<zone>/<member> for the infrastructure, <product>/<property>
+// for product properties and members and <product>/<job>/<property> for job
+// properties and members, where nested names are joined with dots.
+func (is *InstallationSettings) visitSecrets(secret func(name string) bool, visit secretVisitor) {
+	infrastructure := &is.Infrastructure
+	iaas := &infrastructure.IaaSConfig
+	if iaas.VCenterPassword != "" && secret("vcenter_password") {
+		iaas.VCenterPassword = visit("iaas_configuration/vcenter_password", iaas.VCenterPassword)
+	}
+	if iaas.SSHPrivateKey != "" && secret("ssh_private_key") {
+		iaas.SSHPrivateKey = visit("iaas_configuration/ssh_private_key", iaas.SSHPrivateKey)
+	}
+	visitExtraSecrets("", &is.Extras, secret, visit)
+	visitExtraSecrets("infrastructure/", &infrastructure.Extras, secret, visit)
+	visitExtraSecrets("iaas_configuration/", &iaas.Extras, secret, visit)
+	visitExtraSecrets("director_configuration/", &infrastructure.DirectorConfiguration.Extras, secret, visit)
+	for i := range infrastructure.Networks {
+		network := &infrastructure.Networks[i]
+		visitExtraSecrets("networks/"+network.Name+"/", &network.Extras, secret, visit)
+	}
+	for i := range infrastructure.AvailabilityZones {
+		zone := &infrastructure.AvailabilityZones[i]
+		visitExtraSecrets("availability_zones/"+zone.Name+"/", &zone.Extras, secret, visit)
+	}
+	for i := range is.Products {
+		product := &is.Products[i]
+		visitExtraSecrets(secretPath(product, nil, ""), &product.Extras, secret, visit)
+		visitPropertySecrets(secretPath(product, nil, ""), product.Properties, secret, visit)
+		for j := range product.Jobs {
+			job := &product.Jobs[j]
+			visitExtraSecrets(secretPath(product, job, ""), &job.Extras, secret, visit)
+			visitPropertySecrets(secretPath(product, job, ""), job.Properties, secret, visit)
+		}
+	}
+}
+
+// secretPath returns the path of a property of a product, or of one of its
+// jobs when job is not nil, as visitSecrets names it
+func secretPath(product *Products, job *Jobs, property string) string {
+	if job == nil {
+		return productName(product) + "/" + property
+	}
+	return productName(product) + "/" + jobName(job) + "/" + property
+}
+
+// visitExtraSecrets replaces the secrets of the members a model does not know
+// about. Members without secrets keep their original json.
+func visitExtraSecrets(prefix string, x *Extras, secret func(string) bool, visit secretVisitor) {
+	for _, key := range x.ExtraKeys() {
+		raw, _ := x.Extra(key)
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value interface{}
+		if decoder.Decode(&value) != nil {
+			continue
+		}
+		if replaced, changed := visitValueSecrets(prefix+key, key, value, secret, visit); changed {
+			if b, err := json.Marshal(replaced); err == nil {
+				x.SetExtra(key, b)
+			}
+		}
+	}
+}
+
+func visitPropertySecrets(prefix string, properties []Properties, secret func(string) bool, visit secretVisitor) {
+	for i := range properties {
+		p := &properties[i]
+		if value, changed := visitValueSecrets(prefix+p.Name(), p.Name(), p.Value, secret, visit); changed {
+			p.Value = value
+		}
+		visitExtraSecrets(prefix+p.Name()+".", &p.Extras, secret, visit)
+		for k := range p.Options {
+			option := &p.Options[k]
+			visitExtraSecrets(prefix+p.Name()+"."+option.Identifier+".", &option.Extras, secret, visit)
+			visitPropertySecrets(prefix+p.Name()+"."+option.Identifier+".", option.Properties, secret, visit)
+		}
+	}
+}
+
+// visitValueSecrets returns a copy of value with its secrets replaced and
+// whether any secret was replaced. Values without secrets are left untouched so
+// that they are encoded as they were decoded.
+func visitValueSecrets(path, name string, value interface{}, secret func(string) bool, visit secretVisitor) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if v != "" && secret(name) {
+			return visit(path, v), true
+		}
+	case map[string]interface{}:
+		var replaced map[string]interface{}
+		for key, member := range v {
+			memberPath, memberName := path+"."+key, key
+			if identifier, ok := v["identifier"].(string); ok && key == "value" {
+				memberPath, memberName = path+"."+identifier, identifier
+			}
+			if redacted, changed := visitValueSecrets(memberPath, memberName, member, secret, visit); changed {
+				if replaced == nil {
+					replaced = copyMap(v)
+				}
+				replaced[key] = redacted
+			}
+		}
+		if replaced != nil {
+			return replaced, true
+		}
+	case []interface{}:
+		var replaced []interface{}
+		for i, element := range v {
+			if redacted, changed := visitValueSecrets(fmt.Sprintf("%s[%d]", path, i), name, element, secret, visit); changed {
+				if replaced == nil {
+					replaced = append([]interface{}(nil), v...)
+				}
+				replaced[i] = redacted
+			}
+		}
+		if replaced != nil {
+			return replaced, true
+		}
+	}
+	return value, false
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// isSecret reports whether a property value holds credentials
+func isSecret(identifier string, value interface{}) bool {
+	if m, ok := value.(map[string]interface{}); ok {
+		for key := range m {
+			if isSecretName(key) {
+				return true
+			}
+		}
+	}
+	return isSecretName(identifier)
+}
+
+// hasSecrets reports whether a property value is a secret or holds one at any
+// depth, such as the password member of a collection item
+func hasSecrets(name string, value interface{}) bool {
+	if isSecret(name, value) {
+		return true
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var generic interface{}
+	if json.Unmarshal(b, &generic) != nil {
+		return false
+	}
+	_, found := visitValueSecrets(name, name, generic, isSecretName, func(_, secret string) string { return secret })
+	return found
+}
+
+// isSecretName reports whether a property or member name denotes a secret
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "secret", "private_key", "credentials", "passphrase", "auth_json"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,10 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"os"
 
 	"github.com/pivotalservices/opsmanclient"
 )
@@ -47,12 +46,13 @@ func main() {
 	}
 	fmt.Println("Your CF deployment release:", cf.Release)
 
-	d, err := json.Marshal(installation)
+	f, err := os.Create(*saveFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer f.Close()
 
-	err = ioutil.WriteFile(*saveFile, d, 0644)
+	err = installation.Encode(f)
 
 	if err != nil {
 		log.Fatal(err)
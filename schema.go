@@ -0,0 +1,165 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// UnsupportedSchemaVersionError is returned when decoding installation settings
+// of an installation_schema_version there is no model for
+type UnsupportedSchemaVersionError struct {
+	Version string
+}
+
+func (e *UnsupportedSchemaVersionError) Error() string {
+	return fmt.Sprintf("installation_schema_version '%s' is not supported, supported versions are %v", e.Version, SupportedSchemaVersions())
+}
+
+// schemaMigration brings installation settings of a schema version to the
+// layout of the model
+type schemaMigration func(*InstallationSettings) error
+
+var schemaMigrations = map[string]schemaMigration{
+	"1.5": migrateSchema15,
+	"1.6": nil,
+	"1.7": migrateSchema17,
+	"1.8": migrateSchema17,
+}
+
+// SupportedSchemaVersions returns the installation_schema_versions DecodeInstallationSettings supports
+func SupportedSchemaVersions() []string {
+	var versions []string
+	for version := range schemaMigrations {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// DecodeInstallationSettings decodes installation settings, migrating the
+// layout of the document's installation_schema_version to the model. Values
+// filled in by a migration are not encoded back unless they are modified.
+func DecodeInstallationSettings(r io.Reader) (*InstallationSettings, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		InstallationSchemaVersion string `json:"installation_schema_version"`
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("error decoding installation settings, %v", err)
+	}
+	migrate, ok := schemaMigrations[header.InstallationSchemaVersion]
+	if !ok {
+		return nil, &UnsupportedSchemaVersionError{Version: header.InstallationSchemaVersion}
+	}
+
+	var installation InstallationSettings
+	if err = json.NewDecoder(bytes.NewReader(data)).Decode(&installation); err != nil {
+		return nil, fmt.Errorf("error decoding installation settings, %v", err)
+	}
+	if migrate != nil {
+		if err = migrate(&installation); err != nil {
+			return nil, fmt.Errorf("error migrating installation settings from schema %s, %v", header.InstallationSchemaVersion, err)
+		}
+	}
+	return &installation, nil
+}
+
+// migrateSchema15 names properties by their definition, as 1.5 documents
+// have no property identifiers
+func migrateSchema15(installation *InstallationSettings) error {
+	for i := range installation.Products {
+		product := &installation.Products[i]
+		identifyProperties(product.Properties)
+		for j := range product.Jobs {
+			identifyProperties(product.Jobs[j].Properties)
+		}
+	}
+	return nil
+}
+
+func identifyProperties(properties []Properties) {
+	for i := range properties {
+		p := &properties[i]
+		if p.Identifier == "" && p.Definition != "" {
+			p.Identifier = p.Definition
+			p.derive("identifier", p.Identifier)
+		}
+		for k := range p.Options {
+			identifyProperties(p.Options[k].Properties)
+		}
+	}
+}
+
+// migrateSchema17 builds job partitions, which 1.7 documents replaced by a job
+// instance count spread over the availability zones of the product. Jobs of
+// products without availability zones, such as unconfigured staged products,
+// get no partitions.
+func migrateSchema17(installation *InstallationSettings) error {
+	for i := range installation.Products {
+		product := &installation.Products[i]
+		for j := range product.Jobs {
+			if len(product.Jobs[j].Partition) == 0 {
+				product.derivePartitions(&product.Jobs[j])
+			}
+		}
+	}
+	return nil
+}
+
+// derivePartitions spreads the instances of a job over the availability zones
+// of the product, the way 1.7 and later schema versions place them
+func (p *Products) derivePartitions(job *Jobs) {
+	azs := p.AvailabilityZoneReferences
+	if len(azs) == 0 && p.SingletonAvailabilityZoneReference != "" {
+		azs = []string{p.SingletonAvailabilityZoneReference}
+	}
+	var partitions []Partition
+	if len(azs) > 0 {
+		partitions = spreadInstances(job, job.instanceCount(), azs)
+	}
+	job.Partition = partitions
+	job.derive("partitions", partitions)
+}
+
+// derivedPartitions reports whether the partitions of the job were derived by
+// derivePartitions rather than decoded
+func (j *Jobs) derivedPartitions() bool {
+	_, derived := j.derived["partitions"]
+	return derived
+}
+
+// spreadInstances places count instances of a job over the availability zones
+// round robin, the way BOSH does
+func spreadInstances(job *Jobs, count int, azs []string) []Partition {
+	partitions := make([]Partition, len(azs))
+	for i, az := range azs {
+		partitions[i] = Partition{
+			JobReference:              job.GUID,
+			InstallationName:          fmt.Sprintf("%s-partition-%s", job.InstallationName, az),
+			InstanceCount:             count / len(azs),
+			AvailabilityZoneReference: az,
+		}
+		if i < count%len(azs) {
+			partitions[i].InstanceCount++
+		}
+	}
+	return partitions
+}
+
+// instanceCount returns the value of the instances setting of the job
+func (j *Jobs) instanceCount() int {
+	for _, instances := range j.Instances {
+		if instances.Identifier == "instances" {
+			return instances.Value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Schema version aware decoding", func() {
+	var (
+		settingsJSON string
+		is           *InstallationSettings
+		err          error
+	)
+
+	JustBeforeEach(func() {
+		is, err = DecodeInstallationSettings(strings.NewReader(settingsJSON))
+	})
+
+	encodesUnchanged := func() {
+		var compacted, out bytes.Buffer
+		Expect(json.Compact(&compacted, []byte(settingsJSON))).To(Succeed())
+		Expect(is.Encode(&out)).To(Succeed())
+		Expect(strings.TrimSuffix(out.String(), "\n")).To(Equal(compacted.String()))
+	}
+
+	Context("when the schema version is 1.6", func() {
+		BeforeEach(func() {
+			settingsJSON = fixture("installation_settings.json")
+		})
+
+		It("decodes the document as is", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(is.Products[1].Jobs[0].Partition).To(HaveLen(2))
+			encodesUnchanged()
+		})
+	})
+
+	Context("when the schema version is 1.5", func() {
+		BeforeEach(func() {
+			settingsJSON = fixture("installation_settings_1.5.json")
+		})
+
+		It("identifies properties by their definition", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(is.Products[0].Properties[0].Identifier).To(Equal("system_database"))
+			Expect(is.Products[0].Jobs[0].Properties[0].Identifier).To(Equal("vm_credentials"))
+		})
+
+		It("does not encode the migrated identifiers", func() {
+			encodesUnchanged()
+		})
+	})
+
+	Context("when the schema version is 1.7", func() {
+		BeforeEach(func() {
+			settingsJSON = fixture("installation_settings_1.7.json")
+		})
+
+		It("spreads the job instances over the product availability zones", func() {
+			Expect(err).NotTo(HaveOccurred())
+			partitions := is.Products[0].Jobs[0].Partition
+			Expect(partitions).To(HaveLen(2))
+			Expect(partitions[0].InstallationName).To(Equal("diego_cell-partition-6f708192a3b4c5d6e7f8"))
+			Expect(partitions[0].InstanceCount).To(Equal(2))
+			Expect(partitions[1].AvailabilityZoneReference).To(Equal("708192a3b4c5d6e7f809"))
+			Expect(partitions[1].InstanceCount).To(Equal(1))
+		})
+
+		It("leaves the jobs of products without availability zones without partitions", func() {
+			Expect(err).NotTo(HaveOccurred())
+			redis, _ := is.FindProduct("p-redis")
+			Expect(redis.Jobs[0].Partition).To(BeEmpty())
+		})
+
+		It("does not encode the migrated partitions", func() {
+			encodesUnchanged()
+		})
+
+		It("is usable by NewDeployment", func() {
+			deployment := NewDeployment(is, "cf-2a3b4c5d6e7f80918273")
+			Expect(deployment.DiegoCellJobs).To(HaveLen(2))
+		})
+	})
+
+	Context("when the schema version is not supported", func() {
+		BeforeEach(func() {
+			settingsJSON = `{"installation_schema_version": "2.0", "products": []}`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("installation_schema_version '2.0' is not supported, supported versions are [1.5 1.6 1.7 1.8]"))
+			Expect(err).To(BeAssignableToTypeOf(&UnsupportedSchemaVersionError{}))
+		})
+	})
+})
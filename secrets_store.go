@@ -0,0 +1,190 @@
+package opsmanclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	secretStoreVersion    = 1
+	secretStoreKDF        = "pbkdf2-sha256"
+	secretStoreIterations = 100000
+	// maxSecretStoreIterations bounds the work a secret store file can demand
+	maxSecretStoreIterations = 10000000
+)
+
+// SecretStore contains the credentials of installation settings keyed by
+// their path, see ExtractSecrets
+type SecretStore map[string]string
+
+// MissingSecretsError is returned when merging secrets into installation settings
+// that are redacted at paths the secret store has no value for
+type MissingSecretsError struct {
+	Paths []string
+}
+
+func (e *MissingSecretsError) Error() string {
+	return fmt.Sprintf("no secrets stored for %s", strings.Join(e.Paths, ", "))
+}
+
+// encryptedSecretStore is the file format of an encrypted secret store
+type encryptedSecretStore struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ExtractSecrets returns a copy of the installation settings with all the
+// credentials, certificates and keys redacted, and the removed values keyed by
+// their path, such as iaas_configuration/<member>, director_configuration/<member>,
+// <product>/<property> or <product>/<job>/<property>
+func ExtractSecrets(is *InstallationSettings) (*InstallationSettings, SecretStore, error) {
+	redacted, err := is.copy()
+	if err != nil {
+		return nil, nil, err
+	}
+	secrets := make(SecretStore)
+	redacted.visitSecrets(isCredentialName, func(path, secret string) string {
+		secrets[path] = secret
+		return RedactedPlaceholder
+	})
+	return redacted, secrets, nil
+}
+
+// MergeSecrets returns a copy of redacted installation settings with the stored
+// secrets restored. Redacted values the store has no secret for are reported by
+// a MissingSecretsError.
+func MergeSecrets(redacted *InstallationSettings, secrets SecretStore) (*InstallationSettings, error) {
+	merged, err := redacted.copy()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	merged.visitSecrets(isCredentialName, func(path, value string) string {
+		if secret, ok := secrets[path]; ok {
+			return secret
+		}
+		if isRedacted(value) {
+			missing = append(missing, path)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &MissingSecretsError{Paths: missing}
+	}
+	return merged, nil
+}
+
+// Encrypt writes the secret store encrypted with AES-GCM under a key derived
+// from the passphrase
+func (s SecretStore) Encrypt(w io.Writer, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("a passphrase is required to encrypt secrets")
+	}
+	plaintext, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	store := encryptedSecretStore{
+		Version:    secretStoreVersion,
+		KDF:        secretStoreKDF,
+		Iterations: secretStoreIterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err = io.ReadFull(rand.Reader, store.Salt); err != nil {
+		return err
+	}
+	gcm, err := store.cipher(passphrase)
+	if err != nil {
+		return err
+	}
+	store.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, store.Nonce); err != nil {
+		return err
+	}
+	store.Ciphertext = gcm.Seal(nil, store.Nonce, plaintext, nil)
+	return json.NewEncoder(w).Encode(store)
+}
+
+// DecryptSecretStore reads a secret store written by SecretStore.Encrypt
+func DecryptSecretStore(r io.Reader, passphrase string) (SecretStore, error) {
+	var store encryptedSecretStore
+	if err := json.NewDecoder(r).Decode(&store); err != nil {
+		return nil, fmt.Errorf("error reading secret store: %s", err)
+	}
+	if store.Version != secretStoreVersion || store.KDF != secretStoreKDF {
+		return nil, fmt.Errorf("unsupported secret store version %d (%s)", store.Version, store.KDF)
+	}
+	gcm, err := store.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(store.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("error reading secret store: invalid nonce")
+	}
+	plaintext, err := gcm.Open(nil, store.Nonce, store.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("could not decrypt secrets: wrong passphrase or corrupted secret store")
+	}
+	var secrets SecretStore
+	err = json.Unmarshal(plaintext, &secrets)
+	return secrets, err
+}
+
+// ExportSecrets writes the secrets of the installation settings encrypted with
+// the Ops Manager decryption passphrase and returns the redacted settings
+func (c *OpsManAPI) ExportSecrets(installation *InstallationSettings, w io.Writer) (*InstallationSettings, error) {
+	redacted, secrets, err := ExtractSecrets(installation)
+	if err != nil {
+		return nil, err
+	}
+	if err = secrets.Encrypt(w, c.opsmanPassphrase); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+// ImportSecrets restores the secrets written by ExportSecrets into redacted
+// installation settings
+func (c *OpsManAPI) ImportSecrets(redacted *InstallationSettings, r io.Reader) (*InstallationSettings, error) {
+	secrets, err := DecryptSecretStore(r, c.opsmanPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	return MergeSecrets(redacted, secrets)
+}
+
+func (store *encryptedSecretStore) cipher(passphrase string) (cipher.AEAD, error) {
+	if store.Iterations < secretStoreIterations || store.Iterations > maxSecretStoreIterations {
+		return nil, fmt.Errorf("error reading secret store: unsupported key derivation iterations %d", store.Iterations)
+	}
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), store.Salt, store.Iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isCredentialName reports whether a property or member name denotes a secret
+// or a certificate
+func isCredentialName(name string) bool {
+	return isSecretName(name) || strings.Contains(strings.ToLower(name), "cert_pem")
+}
+
+func isRedacted(value string) bool {
+	return value == RedactedPlaceholder || strings.HasPrefix(value, "((redacted:")
+}
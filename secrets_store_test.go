@@ -0,0 +1,117 @@
+package opsmanclient_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Extracting secrets", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	encode := func(is *InstallationSettings) string {
+		var buf bytes.Buffer
+		Expect(is.Encode(&buf)).To(Succeed())
+		return buf.String()
+	}
+
+	It("separates the credentials from the topology", func() {
+		redacted, secrets, err := ExtractSecrets(is)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encode(redacted)).NotTo(ContainSubstring("BEGIN CERTIFICATE"))
+		Expect(encode(redacted)).NotTo(ContainSubstring("wF83U929n84jxt72Er4p"))
+		Expect(secrets).To(HaveKeyWithValue("iaas_configuration/vcenter_password", "password"))
+		Expect(secrets).To(HaveKeyWithValue("cf/router/vm_credentials.password", "oG98b7XF88F7823by4dt"))
+		Expect(secrets).To(HaveKey("cf/ha_proxy/ssl_rsa_certificate.private_key_pem"))
+		Expect(secrets).To(HaveKey("cf/ha_proxy/ssl_rsa_certificate.cert_pem"))
+	})
+
+	It("separates the IaaS and director credentials the model does not know about", func() {
+		aws := NewInstallationSettingsJSON(fixture("installation_settings_aws.json"))
+		redacted, secrets, err := ExtractSecrets(aws)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encode(redacted)).NotTo(ContainSubstring("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"))
+		Expect(secrets).To(HaveKeyWithValue("iaas_configuration/secret_access_key", "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"))
+		Expect(secrets).To(HaveKeyWithValue("director_configuration/s3_blobstore_options.secret_key", "bl0bst0re/S3cr3tK3y/EXAMPLEKEY"))
+		Expect(secrets).To(HaveKeyWithValue("director_configuration/external_database_options.password", "D1r3ct0rDbPa55w0rd"))
+		Expect(secrets).To(HaveKey("iaas_configuration/ssh_private_key"))
+
+		merged, err := MergeSecrets(redacted, secrets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encode(merged)).To(MatchJSON(encode(aws)))
+	})
+
+	It("restores the secrets into the redacted settings", func() {
+		redacted, secrets, err := ExtractSecrets(is)
+		Expect(err).NotTo(HaveOccurred())
+		merged, err := MergeSecrets(redacted, secrets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encode(merged)).To(MatchJSON(encode(is)))
+	})
+
+	It("restores the secrets into settings redacted with a salt", func() {
+		_, secrets, _ := ExtractSecrets(is)
+		redacted, _ := Redact(is, Redaction{Salt: "salt"})
+		merged, err := MergeSecrets(redacted, secrets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encode(merged)).To(MatchJSON(encode(is)))
+	})
+
+	It("lists redacted values without a stored secret", func() {
+		redacted, secrets, _ := ExtractSecrets(is)
+		delete(secrets, "iaas_configuration/vcenter_password")
+		delete(secrets, "cf/router/vm_credentials.password")
+		_, err := MergeSecrets(redacted, secrets)
+		Expect(err).To(MatchError("no secrets stored for cf/router/vm_credentials.password, iaas_configuration/vcenter_password"))
+	})
+
+	Describe("encrypting the secret store", func() {
+		It("decrypts with the same passphrase", func() {
+			var buf bytes.Buffer
+			Expect(SecretStore{"cf/router/vm_credentials.password": "secret"}.Encrypt(&buf, "passphrase")).To(Succeed())
+			Expect(buf.String()).NotTo(ContainSubstring("secret"))
+
+			secrets, err := DecryptSecretStore(&buf, "passphrase")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets).To(Equal(SecretStore{"cf/router/vm_credentials.password": "secret"}))
+		})
+
+		It("fails with another passphrase", func() {
+			var buf bytes.Buffer
+			Expect(SecretStore{"path": "secret"}.Encrypt(&buf, "passphrase")).To(Succeed())
+			_, err := DecryptSecretStore(&buf, "other")
+			Expect(err).To(MatchError("could not decrypt secrets: wrong passphrase or corrupted secret store"))
+		})
+
+		It("rejects weak or excessive key derivation iterations", func() {
+			for _, iterations := range []int{0, 1, 99999, 1000000000} {
+				store := fmt.Sprintf(`{"version":1,"kdf":"pbkdf2-sha256","iterations":%d,"salt":"AAAAAAAAAAAAAAAAAAAAAA==","nonce":"AAAAAAAAAAAAAAAA","ciphertext":""}`, iterations)
+				_, err := DecryptSecretStore(strings.NewReader(store), "passphrase")
+				Expect(err).To(MatchError(fmt.Sprintf("error reading secret store: unsupported key derivation iterations %d", iterations)))
+			}
+		})
+
+		It("requires a passphrase", func() {
+			_, err := c.ExportSecrets(is, &bytes.Buffer{})
+			Expect(err).To(MatchError("a passphrase is required to encrypt secrets"))
+		})
+
+		It("round trips with the Ops Manager passphrase", func() {
+			client := New(opsman.URL, "admin", "admin", "passphrase", false)
+			var buf bytes.Buffer
+			redacted, err := client.ExportSecrets(is, &buf)
+			Expect(err).NotTo(HaveOccurred())
+			merged, err := client.ImportSecrets(redacted, &buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encode(merged)).To(MatchJSON(encode(is)))
+		})
+	})
+})
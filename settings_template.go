@@ -0,0 +1,390 @@
+package opsmanclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var variablePattern = regexp.MustCompile(`\(\(([-\w./]+)\)\)`)
+
+// SettingsTemplate is a YAML or JSON settings document with ((var))
+// placeholders, of the form
+//
+//	products:
+//	  cf:
+//	    properties:
+//	      smtp_address: ((smtp_address))
+//	    jobs:
+//	      router:
+//	        instances: ((router_instances))
+//	        availability_zones: [az1, az2]
+//	        resources: {ram: 4096}
+//	        properties:
+//	          vm_credentials: {identity: vcap, password: ((router_password))}
+type SettingsTemplate struct {
+	document interface{}
+}
+
+// Variables contains the values of template variables by name
+type Variables map[string]interface{}
+
+// UnresolvedVariablesError is returned when interpolating a template
+// references variables without a value
+type UnresolvedVariablesError struct {
+	Names []string
+}
+
+func (e *UnresolvedVariablesError) Error() string {
+	return fmt.Sprintf("unresolved variables: %s", strings.Join(e.Names, ", "))
+}
+
+// settingsOperation is an ops file operation
+type settingsOperation struct {
+	Type  string      `yaml:"type"`
+	Path  string      `yaml:"path"`
+	Value interface{} `yaml:"value"`
+}
+
+// settingsTemplateDocument, templateProduct and templateJob are the layout of
+// an interpolated template
+type (
+	settingsTemplateDocument struct {
+		Products map[string]templateProduct `json:"products"`
+	}
+
+	templateProduct struct {
+		Properties map[string]interface{} `json:"properties"`
+		Jobs       map[string]templateJob `json:"jobs"`
+	}
+
+	templateJob struct {
+		Instances         *int                   `json:"instances"`
+		AvailabilityZones []string               `json:"availability_zones"`
+		Resources         map[string]int         `json:"resources"`
+		Properties        map[string]interface{} `json:"properties"`
+	}
+)
+
+// ParseSettingsTemplate parses a YAML or JSON settings template
+func ParseSettingsTemplate(data []byte) (*SettingsTemplate, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing settings template, %v", err)
+	}
+	return &SettingsTemplate{document: normalizeYAML(document)}, nil
+}
+
+// ApplyOps patches the template with the replace and remove operations of a
+// BOSH style ops file. Paths address map keys, array indexes, array elements
+// by key=value, and - to append; segments ending in ? are created when missing.
+func (t *SettingsTemplate) ApplyOps(data []byte) error {
+	var ops []settingsOperation
+	if err := yaml.Unmarshal(data, &ops); err != nil {
+		return fmt.Errorf("error parsing ops file, %v", err)
+	}
+	for _, op := range ops {
+		if op.Type != "replace" && op.Type != "remove" {
+			return fmt.Errorf("ops path %s: unknown operation type %q", op.Path, op.Type)
+		}
+		if !strings.HasPrefix(op.Path, "/") {
+			return fmt.Errorf("ops path %s: must start with /", op.Path)
+		}
+		op.Value = normalizeYAML(op.Value)
+		document, err := applyOperation(t.document, strings.Split(op.Path, "/")[1:], op)
+		if err != nil {
+			return err
+		}
+		t.document = document
+	}
+	return nil
+}
+
+// Interpolate replaces the ((var)) placeholders of the template and returns
+// the product property and resource updates it describes. Placeholders that
+// make up a whole value are replaced by the typed value of the variable; a
+// dotted name selects a member of a map variable.
+func (t *SettingsTemplate) Interpolate(vars Variables) (*SettingsUpdates, error) {
+	unresolved := make(map[string]bool)
+	document := interpolate(t.document, vars, unresolved)
+	if len(unresolved) > 0 {
+		var names []string
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &UnresolvedVariablesError{Names: names}
+	}
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var template settingsTemplateDocument
+	if err = json.Unmarshal(b, &template); err != nil {
+		return nil, fmt.Errorf("error reading settings template, %v", err)
+	}
+	return template.updates(), nil
+}
+
+// LoadVariablesFile reads variables from a YAML or JSON file
+func LoadVariablesFile(path string) (Variables, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]interface{}
+	if err = yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("error parsing variables file %s, %v", path, err)
+	}
+	for name, value := range vars {
+		vars[name] = normalizeYAML(value)
+	}
+	return Variables(vars), nil
+}
+
+// EnvironmentVariables returns the environment variables starting with prefix
+// as variables named by the rest of their name. Values are kept as strings so
+// that credentials such as 0123 or yes are passed on verbatim.
+func EnvironmentVariables(prefix string) Variables {
+	vars := make(Variables)
+	for name, value := range environment(prefix) {
+		vars[name] = value
+	}
+	return vars
+}
+
+// TypedEnvironmentVariables is like EnvironmentVariables but parses the values
+// as YAML, so that numbers and booleans are typed. Credentials should be passed
+// with EnvironmentVariables under another prefix.
+func TypedEnvironmentVariables(prefix string) Variables {
+	vars := make(Variables)
+	for name, env := range environment(prefix) {
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(env), &value); err != nil || value == nil {
+			value = env
+		}
+		vars[name] = normalizeYAML(value)
+	}
+	return vars
+}
+
+// environment returns the environment variables starting with prefix by the
+// rest of their name
+func environment(prefix string) map[string]string {
+	vars := make(map[string]string)
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], prefix) || kv[0] == prefix {
+			continue
+		}
+		vars[strings.TrimPrefix(kv[0], prefix)] = kv[1]
+	}
+	return vars
+}
+
+// Merge returns the variables overridden by those of other
+func (v Variables) Merge(other Variables) Variables {
+	merged := make(Variables, len(v)+len(other))
+	for name, value := range v {
+		merged[name] = value
+	}
+	for name, value := range other {
+		merged[name] = value
+	}
+	return merged
+}
+
+// ApplyUpdates applies the updates of a settings template to the installation settings
+func (is *InstallationSettings) ApplyUpdates(updates *SettingsUpdates) error {
+	for _, update := range updates.Properties {
+		if err := is.SetProperty(update.Product, update.Job, update.Property, update.Value); err != nil {
+			return err
+		}
+	}
+	for _, update := range updates.Resources {
+		if len(update.AvailabilityZones) > 0 {
+			if err := is.SetAvailabilityZones(update.Product, update.Job, update.AvailabilityZones...); err != nil {
+				return err
+			}
+		}
+		if update.Instances != nil {
+			if err := is.SetInstanceCount(update.Product, update.Job, *update.Instances); err != nil {
+				return err
+			}
+		}
+		for _, identifier := range sortedKeys(update.Resources) {
+			if err := is.SetResource(update.Product, update.Job, identifier, update.Resources[identifier]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v Variables) lookup(name string) (interface{}, bool) {
+	parts := strings.Split(name, ".")
+	value, ok := v[parts[0]]
+	for _, part := range parts[1:] {
+		m, isMap := value.(map[string]interface{})
+		if !ok || !isMap {
+			return nil, false
+		}
+		value, ok = m[part]
+	}
+	return value, ok
+}
+
+func interpolate(node interface{}, vars Variables, unresolved map[string]bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = interpolate(value, vars, unresolved)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = interpolate(value, vars, unresolved)
+		}
+		return s
+	case string:
+		if match := variablePattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			value, ok := vars.lookup(match[1])
+			if !ok {
+				unresolved[match[1]] = true
+			}
+			return value
+		}
+		return variablePattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := variablePattern.FindStringSubmatch(placeholder)[1]
+			value, ok := vars.lookup(name)
+			if !ok {
+				unresolved[name] = true
+				return placeholder
+			}
+			return fmt.Sprint(value)
+		})
+	}
+	return node
+}
+
+func applyOperation(node interface{}, segments []string, op settingsOperation) (interface{}, error) {
+	if len(segments) == 0 {
+		return op.Value, nil
+	}
+	segment := strings.TrimSuffix(segments[0], "?")
+	optional := segment != segments[0]
+	last := len(segments) == 1
+	notFound := fmt.Errorf("ops path %s: %s not found", op.Path, segment)
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, ok := v[segment]
+		if last && op.Type == "remove" {
+			if !ok && !optional {
+				return nil, notFound
+			}
+			delete(v, segment)
+			return v, nil
+		}
+		if !ok && !last {
+			if !optional {
+				return nil, notFound
+			}
+			child = make(map[string]interface{})
+		}
+		child, err := applyOperation(child, segments[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		v[segment] = child
+		return v, nil
+
+	case []interface{}:
+		if segment == "-" {
+			if !last || op.Type != "replace" {
+				return nil, fmt.Errorf("ops path %s: - can only append values", op.Path)
+			}
+			return append(v, op.Value), nil
+		}
+		index := -1
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(v) {
+			index = i
+		} else if kv := strings.SplitN(segment, "=", 2); len(kv) == 2 {
+			for i, element := range v {
+				if m, ok := element.(map[string]interface{}); ok && fmt.Sprint(m[kv[0]]) == kv[1] {
+					index = i
+					break
+				}
+			}
+			if index < 0 && optional && op.Type == "replace" {
+				v = append(v, map[string]interface{}{kv[0]: kv[1]})
+				index = len(v) - 1
+			}
+		}
+		if index < 0 {
+			return nil, notFound
+		}
+		if last && op.Type == "remove" {
+			return append(v[:index], v[index+1:]...), nil
+		}
+		child, err := applyOperation(v[index], segments[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
+
+	case nil:
+		if optional || (last && op.Type == "replace") {
+			return applyOperation(make(map[string]interface{}), segments, op)
+		}
+	}
+	return nil, notFound
+}
+
+func (document *settingsTemplateDocument) updates() *SettingsUpdates {
+	updates := &SettingsUpdates{}
+	for _, product := range sortedKeys(document.Products) {
+		p := document.Products[product]
+		for _, property := range sortedKeys(p.Properties) {
+			updates.Properties = append(updates.Properties, PropertyUpdate{Product: product, Property: property, Value: p.Properties[property]})
+		}
+		for _, job := range sortedKeys(p.Jobs) {
+			j := p.Jobs[job]
+			for _, property := range sortedKeys(j.Properties) {
+				updates.Properties = append(updates.Properties, PropertyUpdate{Product: product, Job: job, Property: property, Value: j.Properties[property]})
+			}
+			if j.Instances != nil || len(j.AvailabilityZones) > 0 || len(j.Resources) > 0 {
+				updates.Resources = append(updates.Resources, ResourceUpdate{
+					Product:           product,
+					Job:               job,
+					Instances:         j.Instances,
+					AvailabilityZones: j.AvailabilityZones,
+					Resources:         j.Resources,
+				})
+			}
+		}
+	}
+	return updates
+}
+
+// sortedKeys returns the sorted keys of a map with string keys
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package opsmanclient_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Settings templates", func() {
+	var (
+		template *SettingsTemplate
+		vars     Variables
+	)
+
+	BeforeEach(func() {
+		var err error
+		template, err = ParseSettingsTemplate([]byte(fixture("settings_template.yml")))
+		Expect(err).NotTo(HaveOccurred())
+		vars, err = LoadVariablesFile("fixtures/settings_vars.yml")
+		Expect(err).NotTo(HaveOccurred())
+		vars = vars.Merge(Variables{"router_password": "s3cret"})
+	})
+
+	It("interpolates variables into typed updates", func() {
+		updates, err := template.Interpolate(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updates.Properties).To(Equal([]PropertyUpdate{
+			{Product: "cf", Property: "smtp_address", Value: "smtp.sys.example.com"},
+			{Product: "cf", Property: "smtp_auth_mechanism", Value: "login"},
+			{Product: "cf", Job: "router", Property: "vm_credentials", Value: map[string]interface{}{"identity": "vcap", "password": "s3cret"}},
+		}))
+		Expect(updates.Resources).To(HaveLen(1))
+		Expect(*updates.Resources[0].Instances).To(Equal(2))
+		Expect(updates.Resources[0].AvailabilityZones).To(Equal([]string{"PCF-Capacity-01", "PCF-Capacity-02"}))
+		Expect(updates.Resources[0].Resources).To(Equal(map[string]int{"ram": 4096}))
+	})
+
+	It("lists the unresolved variables", func() {
+		_, err := template.Interpolate(Variables{"system_domain": "sys.example.com"})
+		Expect(err).To(MatchError("unresolved variables: router_instances, router_password, smtp.auth_mechanism"))
+	})
+
+	It("reads variables from the environment as strings", func() {
+		os.Setenv("OM_VAR_router_password", "0123")
+		os.Setenv("OM_VAR_smtp_password", "yes")
+		defer os.Unsetenv("OM_VAR_router_password")
+		defer os.Unsetenv("OM_VAR_smtp_password")
+		env := EnvironmentVariables("OM_VAR_")
+		Expect(env).To(HaveKeyWithValue("router_password", "0123"))
+		Expect(env).To(HaveKeyWithValue("smtp_password", "yes"))
+	})
+
+	It("reads typed variables from the environment", func() {
+		os.Setenv("OM_TYPED_VAR_router_instances", "4")
+		defer os.Unsetenv("OM_TYPED_VAR_router_instances")
+		updates, err := template.Interpolate(vars.Merge(TypedEnvironmentVariables("OM_TYPED_VAR_")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*updates.Resources[0].Instances).To(Equal(4))
+	})
+
+	It("applies ops file patches", func() {
+		Expect(template.ApplyOps([]byte(`
+- type: replace
+  path: /products/cf/jobs/router/resources/cpu
+  value: 2
+- type: remove
+  path: /products/cf/jobs/router/properties
+- type: replace
+  path: /products/cf/jobs/router/availability_zones/-
+  value: PCF-Capacity-03
+- type: replace
+  path: /products/cf/jobs/diego_cell?/instances
+  value: 6
+`))).To(Succeed())
+		updates, err := template.Interpolate(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updates.Properties).To(HaveLen(2))
+		Expect(updates.Resources).To(HaveLen(2))
+		Expect(updates.Resources[0].Job).To(Equal("diego_cell"))
+		Expect(*updates.Resources[0].Instances).To(Equal(6))
+		Expect(updates.Resources[1].Resources).To(Equal(map[string]int{"ram": 4096, "cpu": 2}))
+		Expect(updates.Resources[1].AvailabilityZones).To(ContainElement("PCF-Capacity-03"))
+	})
+
+	It("fails for ops on missing paths", func() {
+		err := template.ApplyOps([]byte(`[{type: remove, path: /products/p-mysql}]`))
+		Expect(err).To(MatchError("ops path /products/p-mysql: p-mysql not found"))
+	})
+
+	It("applies the updates to installation settings", func() {
+		is := NewInstallationSettingsJSON(fixture("installation_settings.json"))
+		updates, err := template.Interpolate(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(is.ApplyUpdates(updates)).To(Succeed())
+
+		router, _ := is.Products[1].FindJob("router")
+		Expect(router.Partition[0].InstanceCount).To(Equal(1))
+		Expect(router.Partition[1].InstanceCount).To(Equal(1))
+		matches, _ := is.Lookup("products[cf].jobs[router].resources[ram].value")
+		Expect(matches[0].Value).To(BeNumerically("==", 4096))
+		matches, _ = is.Lookup("products[cf].properties[smtp_address].value")
+		Expect(matches[0].Value).To(Equal("smtp.sys.example.com"))
+	})
+})
@@ -0,0 +1,132 @@
+package opsmanclient
+
+// compilationJob is the job of the BOSH compilation vms, which only exist
+// while a product is being deployed
+const compilationJob = "compilation"
+
+// errandJobs are the jobs of the Elastic Runtime errands, whose vms only exist
+// while their errand runs
+var errandJobs = map[string]bool{
+	"push-apps-manager":             true,
+	"push-app-usage-service":        true,
+	"smoke-tests":                   true,
+	"notifications":                 true,
+	"notifications-tests":           true,
+	"notifications-ui":              true,
+	"notifications-ui-tests":        true,
+	"autoscaling":                   true,
+	"autoscaling-register-broker":   true,
+	"autoscaling-destroy-broker":    true,
+	"autoscaling-tests":             true,
+	"diego-acceptance-tests":        true,
+	"acceptance-tests":              true,
+	"acceptance-tests-internetless": true,
+}
+
+// Topology groups the instances of the jobs of all products by availability
+// zone, and reports the jobs with a single instance and the jobs whose
+// instances are unevenly spread over their availability zones or placed in one
+// availability zone while their product spans several. Errands are not flagged,
+// as their vms only run for the errand.
+func (is *InstallationSettings) Topology() *Topology {
+	topology := &Topology{}
+	zones := make(map[string]int)
+	for _, az := range is.Infrastructure.AvailabilityZones {
+		zones[az.GUID] = len(topology.AvailabilityZones)
+		topology.AvailabilityZones = append(topology.AvailabilityZones, AvailabilityZoneTopology{
+			GUID:         az.GUID,
+			Name:         az.Name,
+			Cluster:      az.Cluster,
+			ResourcePool: az.ResourcePool,
+		})
+	}
+
+	for i := range is.Products {
+		product := &is.Products[i]
+		productAZs := make(map[string]bool)
+		for _, job := range product.Jobs {
+			for _, partition := range job.Partition {
+				productAZs[partition.AvailabilityZoneReference] = true
+			}
+		}
+
+		for j := range product.Jobs {
+			job := &product.Jobs[j]
+			if jobName(job) == compilationJob {
+				continue
+			}
+			instances, _ := product.JobInstances(jobName(job), &is.Infrastructure)
+			for _, partition := range job.Partition {
+				if _, ok := zones[partition.AvailabilityZoneReference]; !ok {
+					zones[partition.AvailabilityZoneReference] = len(topology.AvailabilityZones)
+					topology.AvailabilityZones = append(topology.AvailabilityZones, AvailabilityZoneTopology{
+						GUID: partition.AvailabilityZoneReference,
+						Name: partition.AvailabilityZoneReference,
+					})
+				}
+			}
+			for _, instance := range instances {
+				az := &topology.AvailabilityZones[zones[azReference(is, instance.AvailabilityZone)]]
+				az.Instances = append(az.Instances, TopologyInstance{Product: productName(product), JobInstance: instance})
+			}
+
+			switch {
+			case errandJobs[jobName(job)]:
+			case len(instances) == 1:
+				topology.Singletons = append(topology.Singletons, SingletonJob{
+					Product:          productName(product),
+					Job:              jobName(job),
+					AvailabilityZone: instances[0].AvailabilityZone,
+				})
+			case len(instances) > 1:
+				if imbalance := jobImbalance(is, product, job, len(productAZs)); imbalance != nil {
+					topology.Imbalances = append(topology.Imbalances, *imbalance)
+				}
+			}
+		}
+	}
+	return topology
+}
+
+func jobImbalance(is *InstallationSettings, product *Products, job *Jobs, productAZs int) *AvailabilityZoneImbalance {
+	counts := make(map[string]int)
+	min, max, used := -1, 0, 0
+	for _, partition := range job.Partition {
+		az := partition.AvailabilityZoneReference
+		if zone := is.Infrastructure.findAvailabilityZone(az); zone != nil {
+			az = zone.Name
+		}
+		counts[az] += partition.InstanceCount
+		if partition.InstanceCount > 0 {
+			used++
+		}
+	}
+	for _, count := range counts {
+		if min < 0 || count < min {
+			min = count
+		}
+		if count > max {
+			max = count
+		}
+	}
+
+	imbalance := &AvailabilityZoneImbalance{Product: productName(product), Job: jobName(job), Instances: counts}
+	switch {
+	case used == 1 && productAZs > 1:
+		imbalance.Reason = "all instances are in one availability zone"
+	case max-min > 1:
+		imbalance.Reason = "instances are unevenly spread over availability zones"
+	default:
+		return nil
+	}
+	return imbalance
+}
+
+// azReference returns the GUID of the availability zone with the given name,
+// or the name when it is not a known availability zone
+func azReference(is *InstallationSettings, name string) string {
+	if zone := is.Infrastructure.findAvailabilityZone(name); zone != nil {
+		return zone.GUID
+	}
+	return name
+}
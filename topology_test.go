@@ -0,0 +1,81 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Availability zone topology", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("groups the job instances by availability zone", func() {
+		topology := is.Topology()
+		Expect(topology.AvailabilityZones).To(HaveLen(2))
+		az := topology.AvailabilityZones[1]
+		Expect(az.Name).To(Equal("PCF-Capacity-02"))
+		Expect(az.Cluster).NotTo(BeEmpty())
+
+		var jobs []string
+		for _, instance := range az.Instances {
+			jobs = append(jobs, instance.Job)
+		}
+		Expect(jobs).To(Equal([]string{"diego_cell", "doppler", "loggregator_trafficcontroller"}))
+		Expect(az.Instances[0].Product).To(Equal("cf"))
+		Expect(az.Instances[0].IP).To(Equal("192.168.200.32"))
+	})
+
+	It("leaves out the compilation vms", func() {
+		for _, az := range is.Topology().AvailabilityZones {
+			for _, instance := range az.Instances {
+				Expect(instance.Job).NotTo(Equal("compilation"))
+			}
+		}
+	})
+
+	It("flags singleton jobs", func() {
+		topology := is.Topology()
+		var singletons []string
+		for _, singleton := range topology.Singletons {
+			Expect(singleton.AvailabilityZone).To(Equal("PCF-Capacity-01"))
+			singletons = append(singletons, singleton.Product+"/"+singleton.Job)
+		}
+		Expect(singletons).To(Equal([]string{
+			"p-bosh/director",
+			"cf/consul_server", "cf/nats", "cf/etcd_server", "cf/diego_database", "cf/nfs_server",
+			"cf/router", "cf/mysql_proxy", "cf/mysql", "cf/ccdb", "cf/uaadb", "cf/consoledb",
+			"cf/cloud_controller", "cf/ha_proxy", "cf/health_manager", "cf/clock_global",
+			"cf/cloud_controller_worker", "cf/uaa", "cf/diego_brain",
+		}))
+		Expect(topology.Imbalances).To(BeEmpty())
+	})
+
+	It("does not flag errands", func() {
+		job, _ := is.Products[1].FindJob("smoke-tests")
+		job.Partition[0].InstanceCount = 3
+		topology := is.Topology()
+		Expect(topology.Singletons).NotTo(ContainElement(SingletonJob{Product: "cf", Job: "push-apps-manager", AvailabilityZone: "PCF-Capacity-01"}))
+		Expect(topology.Imbalances).To(BeEmpty())
+	})
+
+	It("reports jobs with all instances in one availability zone", func() {
+		Expect(is.SetAvailabilityZones("cf", "diego_cell", "PCF-Capacity-01")).To(Succeed())
+		imbalances := is.Topology().Imbalances
+		Expect(imbalances).To(HaveLen(1))
+		Expect(imbalances[0].Job).To(Equal("diego_cell"))
+		Expect(imbalances[0].Reason).To(Equal("all instances are in one availability zone"))
+	})
+
+	It("reports jobs unevenly spread over availability zones", func() {
+		job, _ := is.Products[1].FindJob("diego_cell")
+		job.Partition[0].InstanceCount = 4
+		imbalances := is.Topology().Imbalances
+		Expect(imbalances).To(HaveLen(1))
+		Expect(imbalances[0].Instances).To(Equal(map[string]int{"PCF-Capacity-01": 4, "PCF-Capacity-02": 1}))
+		Expect(imbalances[0].Reason).To(Equal("instances are unevenly spread over availability zones"))
+	})
+})
@@ -7,9 +7,15 @@ type (
 		Version string `json:"version"`
 	}
 
-	// Deployment information
+	// Deployment information. Jobs contains the active partitions of every job
+	// by identifier; the other job fields list the installation names of the
+	// active partitions of common Elastic Runtime jobs.
 	Deployment struct {
 		Release                     string
+		ProductType                 string
+		ProductVersion              string
+		Stemcell                    Stemcell
+		Jobs                        map[string][]Partition
 		UaaJobs                     []string
 		RouterJobs                  []string
 		CloudControllerJobs         []string
@@ -18,6 +24,35 @@ type (
 		DiegoCellJobs               []string
 		DiegoDatabaseJobs           []string
 		UaaDatabaseJobs             []string
+		DEAJobs                     []string
+		product                     *Products
+		infrastructure              *Infrastructure
+	}
+
+	// Runtime contains the active application runtime backends of a deployment
+	Runtime struct {
+		Type     string           `json:"type"`
+		Backends []RuntimeBackend `json:"backends"`
+	}
+
+	// RuntimeBackend contains the cells, control and database jobs of an
+	// application runtime backend
+	RuntimeBackend struct {
+		Name                    string         `json:"name"`
+		CellJob                 string         `json:"cell_job"`
+		Cells                   int            `json:"cells"`
+		CellsByAvailabilityZone map[string]int `json:"cells_by_availability_zone"`
+		ControlJobs             []string       `json:"control_jobs"`
+		DatabaseJobs            []string       `json:"database_jobs"`
+	}
+
+	// JobInstance contains an instance of a job with its IP address and placement
+	JobInstance struct {
+		Job              string `json:"job"`
+		Index            int    `json:"index"`
+		IP               string `json:"ip"`
+		AvailabilityZone string `json:"availability_zone"`
+		Partition        string `json:"partition"`
 	}
 
 	// Partition information
@@ -26,32 +61,103 @@ type (
 		InstallationName          string `json:"installation_name"`
 		InstanceCount             int    `json:"instance_count"`
 		AvailabilityZoneReference string `json:"availability_zone_reference"`
+		Extras                    `json:"-"`
 	}
 
 	// Products contains all the installed products in an installation
 	Products struct {
-		Name string              `json:"installation_name"`
-		GUID string              `json:"guid"`
-		Type string              `json:"type"`
-		IPS  map[string][]string `json:"ips"`
-		Jobs []Jobs              `json:"jobs"`
+		Name                               string              `json:"installation_name"`
+		GUID                               string              `json:"guid"`
+		Type                               string              `json:"type"`
+		Identifier                         string              `json:"identifier"`
+		ProductVersion                     string              `json:"product_version"`
+		Prepared                           bool                `json:"prepared"`
+		Stemcell                           Stemcell            `json:"stemcell"`
+		SingletonAvailabilityZoneReference string              `json:"singleton_availability_zone_reference"`
+		AvailabilityZoneReferences         []string            `json:"availability_zone_references"`
+		NetworkReference                   string              `json:"network_reference"`
+		InfrastructureNetworkReference     string              `json:"infrastructure_network_reference"`
+		DeploymentNetworkReference         string              `json:"deployment_network_reference"`
+		DisabledPostDeployErrandNames      []string            `json:"disabled_post_deploy_errand_names"`
+		IPS                                map[string][]string `json:"ips"`
+		Properties                         []Properties        `json:"properties"`
+		Jobs                               []Jobs              `json:"jobs"`
+		Extras                             `json:"-"`
+	}
+
+	// Stemcell contains the stemcell used by a product
+	Stemcell struct {
+		Infrastructure string `json:"infrastructure"`
+		Hypervisor     string `json:"hypervisor"`
+		OS             string `json:"os"`
+		Version        string `json:"version"`
+		File           string `json:"file"`
+		Name           string `json:"name"`
+		Extras         `json:"-"`
 	}
 
 	// InstallationSettings contains the installationsettings elements from the json
 	InstallationSettings struct {
-		Infrastructure Infrastructure `json:"infrastructure"`
-		Products       []Products     `json:"products"`
+		GUID                      string         `json:"guid"`
+		InstallationSchemaVersion string         `json:"installation_schema_version"`
+		Infrastructure            Infrastructure `json:"infrastructure"`
+		Products                  []Products     `json:"products"`
+		Extras                    `json:"-"`
 	}
 
 	// Infrastructure contains Infrastructure block elements from the json
 	Infrastructure struct {
-		Type       string            `json:"type"`
-		IaaSConfig IaaSConfiguration `json:"iaas_configuration"`
+		Type                  string                `json:"type"`
+		VMPasswordType        string                `json:"vm_password_type"`
+		DirectorConfiguration DirectorConfiguration `json:"director_configuration"`
+		Networks              []Network             `json:"networks"`
+		AvailabilityZones     []AvailabilityZone    `json:"availability_zones"`
+		IaaSConfig            IaaSConfiguration     `json:"iaas_configuration"`
+		Extras                `json:"-"`
+	}
+
+	// DirectorConfiguration contains the director_configuration block elements from the json
+	DirectorConfiguration struct {
+		ResurrectorEnabled bool     `json:"resurrector_enabled"`
+		NTPServers         []string `json:"ntp_servers"`
+		BlobstoreType      string   `json:"blobstore_type"`
+		DatabaseType       string   `json:"database_type"`
+		Extras             `json:"-"`
+	}
+
+	// Network contains a network block element from the json
+	Network struct {
+		GUID                  string `json:"guid"`
+		Name                  string `json:"name"`
+		IaaSNetworkIdentifier string `json:"iaas_network_identifier"`
+		Subnet                string `json:"subnet"`
+		DNS                   string `json:"dns"`
+		Gateway               string `json:"gateway"`
+		ReservedIPRanges      string `json:"reserved_ip_ranges"`
+		Extras                `json:"-"`
+	}
+
+	// AvailabilityZone contains an availability_zones block element from the json
+	AvailabilityZone struct {
+		GUID         string `json:"guid"`
+		Name         string `json:"name"`
+		Cluster      string `json:"cluster"`
+		ResourcePool string `json:"resource_pool"`
+		Extras       `json:"-"`
 	}
 
 	// IaaSConfiguration contains the IaaSConfiguration block elements from the json
 	IaaSConfiguration struct {
-		SSHPrivateKey string `json:"ssh_private_key"`
+		SSHPrivateKey      string   `json:"ssh_private_key"`
+		Datacenter         string   `json:"datacenter"`
+		VCenterIP          string   `json:"vcenter_ip"`
+		VCenterUsername    string   `json:"vcenter_username"`
+		VCenterPassword    string   `json:"vcenter_password"`
+		Datastores         []string `json:"datastores"`
+		BoshVMFolder       string   `json:"bosh_vm_folder"`
+		BoshTemplateFolder string   `json:"bosh_template_folder"`
+		BoshDiskPath       string   `json:"bosh_disk_path"`
+		Extras             `json:"-"`
 	}
 
 	// Product contains installation settings for a product
@@ -60,6 +166,7 @@ type (
 		IPS            map[string][]string `json:"ips"`
 		Jobs           []Jobs              `json:"jobs"`
 		ProductVersion string              `json:"product_version"`
+		Extras         `json:"-"`
 	}
 
 	// Jobs contains job settings for a product
@@ -68,20 +175,426 @@ type (
 		InstallationName string       `json:"installation_name"`
 		Properties       []Properties `json:"properties"`
 		Instances        []Instances  `json:"instances"`
+		Resources        []Resources  `json:"resources"`
 		Type             string       `json:"type"`
 		GUID             string       `json:"guid"`
 		Partition        []Partition  `json:"partitions"`
+		Extras           `json:"-"`
 	}
 
 	// Properties contains property settings for a job
 	Properties struct {
-		Definition string      `json:"definition"`
-		Value      interface{} `json:"value"`
+		Identifier string           `json:"identifier"`
+		Definition string           `json:"definition"`
+		Value      interface{}      `json:"value"`
+		Options    []PropertyOption `json:"options"`
+		Extras     `json:"-"`
+	}
+
+	// PropertyOption contains a selectable option of a selector property
+	PropertyOption struct {
+		Identifier string       `json:"identifier"`
+		Properties []Properties `json:"properties"`
+		Extras     `json:"-"`
 	}
 
 	// Instances contains instances for a job
 	Instances struct {
 		Identifier string `json:"identifier"`
 		Value      int    `json:"value"`
+		Extras     `json:"-"`
+	}
+
+	// Resources contains a resource setting (ram, cpu, disk) for a job
+	Resources struct {
+		Identifier string `json:"identifier"`
+		Value      int    `json:"value"`
+		Extras     `json:"-"`
+	}
+)
+
+// Ops Manager certificate json types
+type (
+	// Certificate is a certificate and private key pair generated by Ops Manager
+	Certificate struct {
+		Certificate string `json:"certificate"`
+		Key         string `json:"key"`
+	}
+
+	// CertificateAuthority information
+	CertificateAuthority struct {
+		GUID      string `json:"guid"`
+		Issuer    string `json:"issuer"`
+		CreatedOn string `json:"created_on"`
+		ExpiresOn string `json:"expires_on"`
+		Active    bool   `json:"active"`
+		CertPEM   string `json:"cert_pem"`
+	}
+
+	// ProductChange contains the staged change of a product
+	ProductChange struct {
+		GUID   string `json:"guid"`
+		Action string `json:"action"`
+	}
+
+	// CertificateRotation contains the outcome of a RotateCertificates step
+	CertificateRotation struct {
+		Step                          string
+		PreviousAuthority             *CertificateAuthority
+		ActiveAuthority               *CertificateAuthority
+		NewAuthority                  *CertificateAuthority
+		ProductsRequiringApplyChanges []string
+	}
+)
+
+// Ops Manager diagnostic report json types
+type (
+	// DiagnosticReport contains the Ops Manager diagnostic report
+	DiagnosticReport struct {
+		Versions              DiagnosticVersions    `json:"versions"`
+		Stemcells             []string              `json:"stemcells"`
+		InfrastructureType    string                `json:"infrastructure_type"`
+		DirectorConfiguration DirectorConfiguration `json:"director_configuration"`
+		AddedProducts         DiagnosticProducts    `json:"added_products"`
+		// Synthesized is set when the report was built from the installation settings
+		Synthesized bool `json:"-"`
+	}
+
+	// DiagnosticVersions contains the Ops Manager versions of a diagnostic report
+	DiagnosticVersions struct {
+		InstallationSchemaVersion string `json:"installation_schema_version"`
+		MetadataVersion           string `json:"metadata_version"`
+		ReleaseVersion            string `json:"release_version"`
+	}
+
+	// DiagnosticProducts contains the deployed and staged products of a diagnostic report
+	DiagnosticProducts struct {
+		Deployed []DiagnosticProduct `json:"deployed"`
+		Staged   []DiagnosticProduct `json:"staged"`
+	}
+
+	// DiagnosticProduct contains a product of a diagnostic report
+	DiagnosticProduct struct {
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Stemcell string `json:"stemcell"`
+	}
+)
+
+// Ops Manager vm and disk type json types
+type (
+	// VMType contains a vm type offered by the IaaS
+	VMType struct {
+		Name          string `json:"name"`
+		RAM           int    `json:"ram"`
+		CPU           int    `json:"cpu"`
+		EphemeralDisk int    `json:"ephemeral_disk"`
+		Builtin       bool   `json:"builtin,omitempty"`
+	}
+
+	// DiskType contains a persistent disk type offered by the IaaS
+	DiskType struct {
+		Name    string `json:"name"`
+		SizeMB  int    `json:"size_mb"`
+		Builtin bool   `json:"builtin,omitempty"`
+	}
+)
+
+// Ops Manager UAA json types
+type (
+	// UAAUser contains an Ops Manager UAA user
+	UAAUser struct {
+		ID       string           `json:"id,omitempty"`
+		UserName string           `json:"userName"`
+		Password string           `json:"password,omitempty"`
+		Origin   string           `json:"origin,omitempty"`
+		Name     UAAName          `json:"name"`
+		Emails   []UAAEmail       `json:"emails"`
+		Groups   []UAAGroupMember `json:"groups,omitempty"`
+	}
+
+	// UAAName contains the name of a UAA user
+	UAAName struct {
+		GivenName  string `json:"givenName,omitempty"`
+		FamilyName string `json:"familyName,omitempty"`
+	}
+
+	// UAAEmail contains an email address of a UAA user
+	UAAEmail struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	}
+
+	// UAAGroupMember references a group of a UAA user
+	UAAGroupMember struct {
+		Value   string `json:"value"`
+		Display string `json:"display,omitempty"`
+	}
+
+	// UAAClient contains an Ops Manager UAA client
+	UAAClient struct {
+		ClientID             string   `json:"client_id"`
+		ClientSecret         string   `json:"client_secret,omitempty"`
+		Scope                []string `json:"scope,omitempty"`
+		AuthorizedGrantTypes []string `json:"authorized_grant_types,omitempty"`
+		Authorities          []string `json:"authorities,omitempty"`
+		AccessTokenValidity  int      `json:"access_token_validity,omitempty"`
+	}
+)
+
+// Ops Manager property value types
+type (
+	// SimpleCredentials contains the value of a simple_credentials property
+	SimpleCredentials struct {
+		Identity string `json:"identity"`
+		Password string `json:"password"`
+		Salt     string `json:"salt,omitempty"`
+	}
+
+	// RSACertCredentials contains the value of a rsa_cert_credentials property
+	RSACertCredentials struct {
+		CertPEM       string `json:"cert_pem"`
+		PrivateKeyPEM string `json:"private_key_pem"`
+	}
+
+	// CollectionItem contains the properties of an item of a collection property
+	CollectionItem []Properties
+
+	// Selector contains the selected option of a selector property
+	Selector struct {
+		Value      string
+		Properties []Properties
+	}
+)
+
+// Installation settings diff types
+type (
+	// Change is a difference between two installation settings
+	Change struct {
+		Kind             string `json:"kind"`
+		Product          string `json:"product"`
+		Job              string `json:"job,omitempty"`
+		AvailabilityZone string `json:"availability_zone,omitempty"`
+		Property         string `json:"property,omitempty"`
+		From             string `json:"from,omitempty"`
+		To               string `json:"to,omitempty"`
+	}
+
+	// Changes is the list of differences between two installation settings
+	Changes []Change
+)
+
+// Settings template types
+type (
+	// SettingsUpdates contains the product property and resource updates of an
+	// interpolated settings template
+	SettingsUpdates struct {
+		Properties []PropertyUpdate `json:"properties"`
+		Resources  []ResourceUpdate `json:"resources"`
+	}
+
+	// PropertyUpdate sets the value of a product property, or of a job property
+	// when Job is set
+	PropertyUpdate struct {
+		Product  string      `json:"product"`
+		Job      string      `json:"job,omitempty"`
+		Property string      `json:"property"`
+		Value    interface{} `json:"value"`
+	}
+
+	// ResourceUpdate sets the instances, availability zones and resources of a job
+	ResourceUpdate struct {
+		Product           string         `json:"product"`
+		Job               string         `json:"job"`
+		Instances         *int           `json:"instances,omitempty"`
+		AvailabilityZones []string       `json:"availability_zones,omitempty"`
+		Resources         map[string]int `json:"resources,omitempty"`
+	}
+)
+
+// Ops Manager staged product json types
+type (
+	// StagedProperty contains a property of a staged product
+	StagedProperty struct {
+		Value        interface{} `json:"value"`
+		Type         string      `json:"type,omitempty"`
+		Configurable bool        `json:"configurable"`
+		Credential   bool        `json:"credential"`
+	}
+
+	// NamedReference references a network or availability zone by name
+	NamedReference struct {
+		Name string `json:"name"`
+	}
+
+	// NetworksAndAZs contains the network and availability zones of a staged product
+	NetworksAndAZs struct {
+		Network                   *NamedReference  `json:"network,omitempty"`
+		SingletonAvailabilityZone *NamedReference  `json:"singleton_availability_zone,omitempty"`
+		OtherAvailabilityZones    []NamedReference `json:"other_availability_zones,omitempty"`
+	}
+
+	// JobResourceConfig contains the resource configuration of a staged job.
+	// Instances and the persistent disk size may be "automatic".
+	JobResourceConfig struct {
+		Instances      interface{}        `json:"instances"`
+		InstanceType   InstanceTypeRef    `json:"instance_type"`
+		PersistentDisk *PersistentDiskRef `json:"persistent_disk,omitempty"`
+	}
+
+	// InstanceTypeRef references a vm type
+	InstanceTypeRef struct {
+		ID string `json:"id"`
+	}
+
+	// PersistentDiskRef contains the persistent disk size of a job
+	PersistentDiskRef struct {
+		SizeMB string `json:"size_mb"`
+	}
+
+	// Errand contains the errand settings of a staged product. PostDeploy and
+	// PreDelete are true, false, "when-changed" or null.
+	Errand struct {
+		Name       string      `json:"name"`
+		PostDeploy interface{} `json:"post_deploy,omitempty"`
+		PreDelete  interface{} `json:"pre_delete,omitempty"`
+	}
+
+	// ProductConfig is the desired state of a staged product, see ParseProductConfig
+	ProductConfig struct {
+		Product                   string                         `json:"product"`
+		Properties                map[string]interface{}         `json:"properties"`
+		Network                   string                         `json:"network"`
+		SingletonAvailabilityZone string                         `json:"singleton_availability_zone"`
+		AvailabilityZones         []string                       `json:"availability_zones"`
+		Resources                 map[string]JobResourceSettings `json:"resources"`
+		Errands                   map[string]ErrandSettings      `json:"errands"`
+	}
+
+	// JobResourceSettings is the desired resource configuration of a job
+	JobResourceSettings struct {
+		Instances        *int   `json:"instances"`
+		InstanceType     string `json:"instance_type"`
+		PersistentDiskMB string `json:"persistent_disk_mb"`
+	}
+
+	// ErrandSettings is the desired state of an errand
+	ErrandSettings struct {
+		PostDeploy interface{} `json:"post_deploy"`
+		PreDelete  interface{} `json:"pre_delete"`
+	}
+)
+
+// Availability zone topology types
+type (
+	// Topology contains the job instances of all products grouped by
+	// availability zone, with the jobs that are not highly available
+	Topology struct {
+		AvailabilityZones []AvailabilityZoneTopology  `json:"availability_zones"`
+		Singletons        []SingletonJob              `json:"singletons"`
+		Imbalances        []AvailabilityZoneImbalance `json:"imbalances"`
+	}
+
+	// AvailabilityZoneTopology contains the job instances placed in an availability zone
+	AvailabilityZoneTopology struct {
+		GUID         string             `json:"guid"`
+		Name         string             `json:"name"`
+		Cluster      string             `json:"cluster"`
+		ResourcePool string             `json:"resource_pool"`
+		Instances    []TopologyInstance `json:"instances"`
+	}
+
+	// TopologyInstance is a job instance of a product
+	TopologyInstance struct {
+		Product string `json:"product"`
+		JobInstance
+	}
+
+	// SingletonJob is a job with a single instance
+	SingletonJob struct {
+		Product          string `json:"product"`
+		Job              string `json:"job"`
+		AvailabilityZone string `json:"availability_zone"`
+	}
+
+	// AvailabilityZoneImbalance is a job whose instances are unevenly spread
+	// over availability zones
+	AvailabilityZoneImbalance struct {
+		Product   string         `json:"product"`
+		Job       string         `json:"job"`
+		Instances map[string]int `json:"instances"`
+		Reason    string         `json:"reason"`
+	}
+)
+
+// Capacity types
+type (
+	// CapacityReport contains the resources the job instances of an
+	// installation use, per product and per availability zone
+	CapacityReport struct {
+		Products          []CapacityUsage   `json:"products"`
+		AvailabilityZones []CapacityUsage   `json:"availability_zones"`
+		Total             CapacityUsage     `json:"total"`
+		DiegoCells        DiegoCellCapacity `json:"diego_cells"`
+	}
+
+	// CapacityUsage contains the resources used by a product or in an availability zone
+	CapacityUsage struct {
+		Name             string `json:"name"`
+		Instances        int    `json:"instances"`
+		CPU              int    `json:"cpu"`
+		MemoryMB         int    `json:"memory_mb"`
+		EphemeralDiskMB  int    `json:"ephemeral_disk_mb"`
+		PersistentDiskMB int    `json:"persistent_disk_mb"`
+	}
+
+	// DiegoCellCapacity contains the memory and disk the Diego cells offer to containers
+	DiegoCellCapacity struct {
+		Cells             int            `json:"cells"`
+		MemoryMB          int            `json:"memory_mb"`
+		DiskMB            int            `json:"disk_mb"`
+		AvailabilityZones map[string]int `json:"availability_zones"`
+	}
+)
+
+// Database topology types
+type (
+	// Database contains where a logical Elastic Runtime database runs.
+	// CredentialReference is the path of its password in the secret store
+	// ExtractSecrets returns, such as cf/mysql/ccdb_credentials.password.
+	Database struct {
+		Name                string   `json:"name"`
+		Engine              string   `json:"engine"`
+		Jobs                []string `json:"jobs,omitempty"`
+		Hosts               []string `json:"hosts"`
+		Port                int      `json:"port,omitempty"`
+		DatabaseName        string   `json:"database_name"`
+		CredentialReference string   `json:"credential_reference"`
+	}
+)
+
+// Validation types
+type (
+	// Finding is a misconfiguration found by Validate
+	Finding struct {
+		Severity string `json:"severity"`
+		Check    string `json:"check"`
+		Product  string `json:"product"`
+		Job      string `json:"job,omitempty"`
+		Message  string `json:"message"`
+	}
+)
+
+// Inventory types
+type (
+	// BOSHInstanceGroup maps a job of a deployment in an availability zone to
+	// the BOSH deployment and instance group running it
+	BOSHInstanceGroup struct {
+		Deployment       string   `json:"deployment"`
+		Job              string   `json:"job"`
+		InstanceGroup    string   `json:"instance_group"`
+		AvailabilityZone string   `json:"availability_zone"`
+		Instances        int      `json:"instances"`
+		IPs              []string `json:"ips"`
 	}
 )
@@ -0,0 +1,183 @@
+package opsmanclient
+
+// The installation settings types keep the members they do not model, see Extras
+
+// UnmarshalJSON decodes a InstallationSettings, retaining unknown members
+func (is *InstallationSettings) UnmarshalJSON(data []byte) error {
+	type plain InstallationSettings
+	return unmarshalObject(data, (*plain)(is), &is.Extras)
+}
+
+// MarshalJSON encodes a InstallationSettings, including the retained unknown members
+func (is InstallationSettings) MarshalJSON() ([]byte, error) {
+	type plain InstallationSettings
+	return marshalObject((*plain)(&is), &is.Extras)
+}
+
+// UnmarshalJSON decodes a Infrastructure, retaining unknown members
+func (i *Infrastructure) UnmarshalJSON(data []byte) error {
+	type plain Infrastructure
+	return unmarshalObject(data, (*plain)(i), &i.Extras)
+}
+
+// MarshalJSON encodes a Infrastructure, including the retained unknown members
+func (i Infrastructure) MarshalJSON() ([]byte, error) {
+	type plain Infrastructure
+	return marshalObject((*plain)(&i), &i.Extras)
+}
+
+// UnmarshalJSON decodes a DirectorConfiguration, retaining unknown members
+func (d *DirectorConfiguration) UnmarshalJSON(data []byte) error {
+	type plain DirectorConfiguration
+	return unmarshalObject(data, (*plain)(d), &d.Extras)
+}
+
+// MarshalJSON encodes a DirectorConfiguration, including the retained unknown members
+func (d DirectorConfiguration) MarshalJSON() ([]byte, error) {
+	type plain DirectorConfiguration
+	return marshalObject((*plain)(&d), &d.Extras)
+}
+
+// UnmarshalJSON decodes a Network, retaining unknown members
+func (n *Network) UnmarshalJSON(data []byte) error {
+	type plain Network
+	return unmarshalObject(data, (*plain)(n), &n.Extras)
+}
+
+// MarshalJSON encodes a Network, including the retained unknown members
+func (n Network) MarshalJSON() ([]byte, error) {
+	type plain Network
+	return marshalObject((*plain)(&n), &n.Extras)
+}
+
+// UnmarshalJSON decodes a AvailabilityZone, retaining unknown members
+func (az *AvailabilityZone) UnmarshalJSON(data []byte) error {
+	type plain AvailabilityZone
+	return unmarshalObject(data, (*plain)(az), &az.Extras)
+}
+
+// MarshalJSON encodes a AvailabilityZone, including the retained unknown members
+func (az AvailabilityZone) MarshalJSON() ([]byte, error) {
+	type plain AvailabilityZone
+	return marshalObject((*plain)(&az), &az.Extras)
+}
+
+// UnmarshalJSON decodes a IaaSConfiguration, retaining unknown members
+func (i *IaaSConfiguration) UnmarshalJSON(data []byte) error {
+	type plain IaaSConfiguration
+	return unmarshalObject(data, (*plain)(i), &i.Extras)
+}
+
+// MarshalJSON encodes a IaaSConfiguration, including the retained unknown members
+func (i IaaSConfiguration) MarshalJSON() ([]byte, error) {
+	type plain IaaSConfiguration
+	return marshalObject((*plain)(&i), &i.Extras)
+}
+
+// UnmarshalJSON decodes a Products, retaining unknown members
+func (p *Products) UnmarshalJSON(data []byte) error {
+	type plain Products
+	return unmarshalObject(data, (*plain)(p), &p.Extras)
+}
+
+// MarshalJSON encodes a Products, including the retained unknown members
+func (p Products) MarshalJSON() ([]byte, error) {
+	type plain Products
+	return marshalObject((*plain)(&p), &p.Extras)
+}
+
+// UnmarshalJSON decodes a Stemcell, retaining unknown members
+func (s *Stemcell) UnmarshalJSON(data []byte) error {
+	type plain Stemcell
+	return unmarshalObject(data, (*plain)(s), &s.Extras)
+}
+
+// MarshalJSON encodes a Stemcell, including the retained unknown members
+func (s Stemcell) MarshalJSON() ([]byte, error) {
+	type plain Stemcell
+	return marshalObject((*plain)(&s), &s.Extras)
+}
+
+// UnmarshalJSON decodes a Product, retaining unknown members
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type plain Product
+	return unmarshalObject(data, (*plain)(p), &p.Extras)
+}
+
+// MarshalJSON encodes a Product, including the retained unknown members
+func (p Product) MarshalJSON() ([]byte, error) {
+	type plain Product
+	return marshalObject((*plain)(&p), &p.Extras)
+}
+
+// UnmarshalJSON decodes a Jobs, retaining unknown members
+func (j *Jobs) UnmarshalJSON(data []byte) error {
+	type plain Jobs
+	return unmarshalObject(data, (*plain)(j), &j.Extras)
+}
+
+// MarshalJSON encodes a Jobs, including the retained unknown members
+func (j Jobs) MarshalJSON() ([]byte, error) {
+	type plain Jobs
+	return marshalObject((*plain)(&j), &j.Extras)
+}
+
+// UnmarshalJSON decodes a Properties, retaining unknown members
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	type plain Properties
+	return unmarshalObject(data, (*plain)(p), &p.Extras)
+}
+
+// MarshalJSON encodes a Properties, including the retained unknown members
+func (p Properties) MarshalJSON() ([]byte, error) {
+	type plain Properties
+	return marshalObject((*plain)(&p), &p.Extras)
+}
+
+// UnmarshalJSON decodes a PropertyOption, retaining unknown members
+func (o *PropertyOption) UnmarshalJSON(data []byte) error {
+	type plain PropertyOption
+	return unmarshalObject(data, (*plain)(o), &o.Extras)
+}
+
+// MarshalJSON encodes a PropertyOption, including the retained unknown members
+func (o PropertyOption) MarshalJSON() ([]byte, error) {
+	type plain PropertyOption
+	return marshalObject((*plain)(&o), &o.Extras)
+}
+
+// UnmarshalJSON decodes a Instances, retaining unknown members
+func (i *Instances) UnmarshalJSON(data []byte) error {
+	type plain Instances
+	return unmarshalObject(data, (*plain)(i), &i.Extras)
+}
+
+// MarshalJSON encodes a Instances, including the retained unknown members
+func (i Instances) MarshalJSON() ([]byte, error) {
+	type plain Instances
+	return marshalObject((*plain)(&i), &i.Extras)
+}
+
+// UnmarshalJSON decodes a Resources, retaining unknown members
+func (r *Resources) UnmarshalJSON(data []byte) error {
+	type plain Resources
+	return unmarshalObject(data, (*plain)(r), &r.Extras)
+}
+
+// MarshalJSON encodes a Resources, including the retained unknown members
+func (r Resources) MarshalJSON() ([]byte, error) {
+	type plain Resources
+	return marshalObject((*plain)(&r), &r.Extras)
+}
+
+// UnmarshalJSON decodes a Partition, retaining unknown members
+func (p *Partition) UnmarshalJSON(data []byte) error {
+	type plain Partition
+	return unmarshalObject(data, (*plain)(p), &p.Extras)
+}
+
+// MarshalJSON encodes a Partition, including the retained unknown members
+func (p Partition) MarshalJSON() ([]byte, error) {
+	type plain Partition
+	return marshalObject((*plain)(&p), &p.Extras)
+}
@@ -0,0 +1,250 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nhttp "net/http"
+	urllib "net/url"
+)
+
+// Ops Manager RBAC roles
+const (
+	RoleFullControl       = "opsman.full_control"
+	RoleFullView          = "opsman.full_view"
+	RoleRestrictedControl = "opsman.restricted_control"
+	RoleRestrictedView    = "opsman.restricted_view"
+)
+
+const uaaPageSize = 100
+
+// ListUsers returns all the users of the Ops Manager UAA
+func (c *OpsManAPI) ListUsers() ([]UAAUser, error) {
+	token, err := c.uaaToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var users []UAAUser
+	for start := 1; ; start += uaaPageSize {
+		var page struct {
+			Resources    []UAAUser `json:"resources"`
+			TotalResults int       `json:"totalResults"`
+		}
+		path := fmt.Sprintf("/Users?startIndex=%d&count=%d", start, uaaPageSize)
+		if err = c.uaaRequest(token, "GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		users = append(users, page.Resources...)
+		if len(page.Resources) == 0 || len(users) >= page.TotalResults {
+			return users, nil
+		}
+	}
+}
+
+// CreateUser creates an Ops Manager UAA user with the given password
+func (c *OpsManAPI) CreateUser(userName, email, password string) (*UAAUser, error) {
+	token, err := c.uaaToken()
+	if err != nil {
+		return nil, err
+	}
+
+	user := UAAUser{
+		UserName: userName,
+		Password: password,
+		Origin:   "uaa",
+		Emails:   []UAAEmail{{Value: email, Primary: true}},
+	}
+	var created UAAUser
+	if err = c.uaaRequest(token, "POST", "/Users", user, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteUser deletes the Ops Manager UAA user with the given id
+func (c *OpsManAPI) DeleteUser(userID string) error {
+	token, err := c.uaaToken()
+	if err != nil {
+		return err
+	}
+	return c.uaaRequest(token, "DELETE", "/Users/"+userID, nil, nil)
+}
+
+// AssignUserRole adds the user with the given id to an Ops Manager RBAC role
+func (c *OpsManAPI) AssignUserRole(userID, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	token, err := c.uaaToken()
+	if err != nil {
+		return err
+	}
+
+	var groups struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"resources"`
+	}
+	filter := urllib.QueryEscape(fmt.Sprintf(`displayName eq "%s"`, role))
+	if err = c.uaaRequest(token, "GET", "/Groups?filter="+filter, nil, &groups); err != nil {
+		return err
+	}
+	if len(groups.Resources) == 0 {
+		return fmt.Errorf("role %s not found in uaa", role)
+	}
+
+	member := map[string]string{
+		"origin": "uaa",
+		"type":   "USER",
+		"value":  userID,
+	}
+	return c.uaaRequest(token, "POST", fmt.Sprintf("/Groups/%s/members", groups.Resources[0].ID), member, nil)
+}
+
+// ListClients returns all the clients of the Ops Manager UAA
+func (c *OpsManAPI) ListClients() ([]UAAClient, error) {
+	token, err := c.uaaToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []UAAClient
+	for start := 1; ; start += uaaPageSize {
+		var page struct {
+			Resources    []UAAClient `json:"resources"`
+			TotalResults int         `json:"totalResults"`
+		}
+		path := fmt.Sprintf("/oauth/clients?startIndex=%d&count=%d", start, uaaPageSize)
+		if err = c.uaaRequest(token, "GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		clients = append(clients, page.Resources...)
+		if len(page.Resources) == 0 || len(clients) >= page.TotalResults {
+			return clients, nil
+		}
+	}
+}
+
+// CreateClient creates an Ops Manager UAA client
+func (c *OpsManAPI) CreateClient(client UAAClient) (*UAAClient, error) {
+	token, err := c.uaaToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var created UAAClient
+	if err = c.uaaRequest(token, "POST", "/oauth/clients", client, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteClient deletes the Ops Manager UAA client with the given id
+func (c *OpsManAPI) DeleteClient(clientID string) error {
+	token, err := c.uaaToken()
+	if err != nil {
+		return err
+	}
+	return c.uaaRequest(token, "DELETE", "/oauth/clients/"+clientID, nil, nil)
+}
+
+// AssignClientRole grants an Ops Manager RBAC role to the client with the
+// given id by adding it to the client authorities
+func (c *OpsManAPI) AssignClientRole(clientID, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	token, err := c.uaaToken()
+	if err != nil {
+		return err
+	}
+
+	var client UAAClient
+	if err = c.uaaRequest(token, "GET", "/oauth/clients/"+clientID, nil, &client); err != nil {
+		return err
+	}
+	for _, authority := range client.Authorities {
+		if authority == role {
+			return nil
+		}
+	}
+	client.Authorities = append(client.Authorities, role)
+	return c.uaaRequest(token, "PUT", "/oauth/clients/"+clientID, client, nil)
+}
+
+func (c *OpsManAPI) uaaURL() string {
+	u, err := urllib.Parse(c.opsmanURL)
+	if err != nil || u.Host == "" {
+		return c.opsmanURL + "/uaa"
+	}
+	return u.Scheme + "://" + u.Host + "/uaa"
+}
+
+func (c *OpsManAPI) uaaToken() (string, error) {
+	c.logger.Debug("aquiring your token from: ", c.uaaURL())
+	token, err := c.UAATokenGetter(c.uaaURL(), c.opsmanUsername, c.opsmanPassword, "opsman", "")
+	if err != nil {
+		return "", fmt.Errorf("error getting uaa token, %v", err)
+	}
+	return token, nil
+}
+
+// httpDoer is implemented by HTTP clients that can send any request
+type httpDoer interface {
+	Do(req *nhttp.Request) (*nhttp.Response, error)
+}
+
+// uaaRequest sends reqBody as JSON to the given Ops Manager UAA path with the
+// bearer token and decodes the JSON response into respBody, when respBody is not nil
+func (c *OpsManAPI) uaaRequest(token, method, path string, reqBody, respBody interface{}) error {
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := nhttp.NewRequest(method, c.uaaURL()+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client, ok := c.HTTPClient.(httpDoer)
+	if !ok {
+		return errors.New("the http client cannot send uaa requests, it needs a Do method")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return unexpectedStatusErr(resp, nhttp.StatusOK)
+	}
+	defer resp.Body.Close()
+
+	if respBody == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+		return fmt.Errorf("error unmarshalling uaa %s json response: %s", path, err)
+	}
+	return nil
+}
+
+func validateRole(role string) error {
+	switch role {
+	case RoleFullControl, RoleFullView, RoleRestrictedControl, RoleRestrictedView:
+		return nil
+	}
+	return fmt.Errorf("%s is not an Ops Manager role", role)
+}
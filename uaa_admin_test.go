@@ -0,0 +1,104 @@
+package opsmanclient_test
+
+import (
+	"io"
+	nhttp "net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+type getPostClient struct{}
+
+func (getPostClient) Get(url string) (*nhttp.Response, error) {
+	return nhttp.Get(url)
+}
+
+func (getPostClient) Post(url, bodyType string, body io.Reader) (*nhttp.Response, error) {
+	return nhttp.Post(url, bodyType, body)
+}
+
+var _ = Describe("UAA administration", func() {
+	var tokenURL string
+
+	JustBeforeEach(func() {
+		opsman.InitializeUAATest("test-token", []opsmanclient.UAAUser{
+			{ID: "admin-id", UserName: "admin"},
+		}, []opsmanclient.UAAClient{
+			{ClientID: "opsman"},
+			{ClientID: "automation", Authorities: []string{"scim.read"}},
+		})
+		c.UAATokenGetter = func(uaaURL, username, password, clientID, clientSecret string) (string, error) {
+			tokenURL = uaaURL
+			return "test-token", nil
+		}
+	})
+
+	Describe("users", func() {
+		It("lists the users with a token from the ops manager uaa", func() {
+			users, err := c.ListUsers()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].UserName).To(Equal("admin"))
+			Expect(tokenURL).To(Equal(opsman.URL + "/uaa"))
+		})
+
+		It("only needs an http client that can send requests", func() {
+			c.HTTPClient = nhttp.DefaultClient
+			users, err := c.ListUsers()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+
+			c.HTTPClient = getPostClient{}
+			_, err = c.ListUsers()
+			Expect(err).To(MatchError("the http client cannot send uaa requests, it needs a Do method"))
+		})
+
+		It("creates and deletes a user", func() {
+			user, err := c.CreateUser("operator", "operator@example.com", "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.ID).To(Equal("user-id-2"))
+			Expect(opsman.UAAUsers[1].Password).To(Equal("secret"))
+
+			Expect(c.DeleteUser(user.ID)).To(Succeed())
+			Expect(opsman.UAAUsers).To(HaveLen(1))
+		})
+
+		It("assigns a role", func() {
+			Expect(c.AssignUserRole("admin-id", opsmanclient.RoleRestrictedView)).To(Succeed())
+			Expect(opsman.UAAGroupMembers["opsman.restricted_view-id"]).To(Equal([]string{"admin-id"}))
+		})
+
+		It("rejects unknown roles", func() {
+			err := c.AssignUserRole("admin-id", "cloud_controller.admin")
+			Expect(err).To(MatchError("cloud_controller.admin is not an Ops Manager role"))
+		})
+	})
+
+	Describe("clients", func() {
+		It("lists the clients", func() {
+			clients, err := c.ListClients()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clients).To(HaveLen(2))
+		})
+
+		It("creates and deletes a client", func() {
+			_, err := c.CreateClient(opsmanclient.UAAClient{
+				ClientID:             "backup",
+				ClientSecret:         "secret",
+				AuthorizedGrantTypes: []string{"client_credentials"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsman.UAAClients).To(HaveLen(3))
+
+			Expect(c.DeleteClient("backup")).To(Succeed())
+			Expect(opsman.UAAClients).To(HaveLen(2))
+		})
+
+		It("assigns a role as an authority", func() {
+			Expect(c.AssignClientRole("automation", opsmanclient.RoleFullControl)).To(Succeed())
+			Expect(opsman.UAAClients[1].Authorities).To(Equal([]string{"scim.read", "opsman.full_control"}))
+		})
+	})
+})
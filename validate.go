@@ -0,0 +1,201 @@
+package opsmanclient
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Severities of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Checks performed by Validate
+const (
+	CheckUnknownAvailabilityZone = "unknown_availability_zone"
+	CheckIPOutsideSubnet         = "ip_outside_subnet"
+	CheckIPInReservedRange       = "ip_in_reserved_range"
+	CheckDuplicateIP             = "duplicate_ip"
+	CheckEvenQuorum              = "even_quorum"
+	CheckCriticalJobStopped      = "critical_job_without_instances"
+)
+
+// quorumJobs need an odd number of instances to keep a quorum
+var quorumJobs = []string{"consul_server", "etcd_server", "mysql"}
+
+// criticalJobs are the jobs of a product that must have instances
+var criticalJobs = map[string][]string{
+	"p-bosh": {"director"},
+	"cf": {
+		"nats", "consul_server", "etcd_server", "diego_database", "router", "uaa",
+		"cloud_controller", "cloud_controller_worker", "clock_global", "diego_brain",
+		"doppler", "loggregator_trafficcontroller",
+	},
+}
+
+// Validate checks the installation settings for jobs placed in unknown
+// availability zones, IPs outside the subnet of their network or inside its
+// reserved ranges, IPs assigned to more than one job, quorum jobs with an even
+// number of instances, and critical jobs without instances
+func (is *InstallationSettings) Validate() []Finding {
+	var findings []Finding
+	owners := make(map[string][]string)
+
+	for i := range is.Products {
+		product := &is.Products[i]
+		name := productName(product)
+		network := is.productNetwork(product)
+
+		jobs := make(map[string]*Jobs)
+		checked := make(map[string]bool)
+		checkAddress := func(job, ip string) {
+			owners[ip] = appendOwner(owners[ip], name+"/"+job)
+			if network != nil && !checked[job+"/"+ip] {
+				checked[job+"/"+ip] = true
+				findings = append(findings, checkIP(name, job, ip, network)...)
+			}
+		}
+		for j := range product.Jobs {
+			job := &product.Jobs[j]
+			jobs[job.GUID] = job
+			for _, partition := range job.Partition {
+				if is.Infrastructure.findAvailabilityZone(partition.AvailabilityZoneReference) == nil {
+					findings = append(findings, Finding{
+						Severity: SeverityError,
+						Check:    CheckUnknownAvailabilityZone,
+						Product:  name,
+						Job:      jobName(job),
+						Message:  fmt.Sprintf("partition %s references unknown availability zone %s", partition.InstallationName, partition.AvailabilityZoneReference),
+					})
+				}
+			}
+			if property, err := job.FindProperty("static_ips"); err == nil {
+				if ips, err := property.AsString(); err == nil {
+					for _, ip := range strings.Split(ips, ",") {
+						if ip = strings.TrimSpace(ip); ip != "" {
+							checkAddress(jobName(job), ip)
+						}
+					}
+				}
+			}
+		}
+
+		for _, key := range sortedKeys(product.IPS) {
+			job := key
+			if j, ok := jobs[strings.SplitN(key, "-partition-", 2)[0]]; ok {
+				job = jobName(j)
+			}
+			for _, ip := range product.IPS[key] {
+				checkAddress(job, ip)
+			}
+		}
+
+		for _, job := range quorumJobs {
+			if j, err := product.FindJob(job); err == nil {
+				if count := j.instanceCount(); count > 0 && count%2 == 0 {
+					findings = append(findings, Finding{
+						Severity: SeverityWarning,
+						Check:    CheckEvenQuorum,
+						Product:  name,
+						Job:      job,
+						Message:  fmt.Sprintf("%d instances cannot keep a quorum when half of them fail, use an odd number", count),
+					})
+				}
+			}
+		}
+		for _, job := range criticalJobs[name] {
+			if j, err := product.FindJob(job); err == nil && j.instanceCount() == 0 {
+				findings = append(findings, Finding{
+					Severity: SeverityError,
+					Check:    CheckCriticalJobStopped,
+					Product:  name,
+					Job:      job,
+					Message:  "critical job has no instances",
+				})
+			}
+		}
+	}
+
+	for _, ip := range sortedKeys(owners) {
+		if len(owners[ip]) > 1 {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Check:    CheckDuplicateIP,
+				Product:  strings.SplitN(owners[ip][0], "/", 2)[0],
+				Job:      strings.SplitN(owners[ip][0], "/", 2)[1],
+				Message:  fmt.Sprintf("ip %s is assigned to %s", ip, strings.Join(owners[ip], ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// productNetwork returns the network the product is deployed to
+func (is *InstallationSettings) productNetwork(product *Products) *Network {
+	for _, reference := range []string{product.NetworkReference, product.DeploymentNetworkReference, product.InfrastructureNetworkReference} {
+		for i := range is.Infrastructure.Networks {
+			if reference != "" && is.Infrastructure.Networks[i].GUID == reference {
+				return &is.Infrastructure.Networks[i]
+			}
+		}
+	}
+	if len(is.Infrastructure.Networks) == 1 {
+		return &is.Infrastructure.Networks[0]
+	}
+	return nil
+}
+
+func checkIP(product, job, address string, network *Network) []Finding {
+	finding := Finding{Severity: SeverityError, Product: product, Job: job}
+	ip := net.ParseIP(address)
+	_, subnet, err := net.ParseCIDR(network.Subnet)
+	switch {
+	case ip == nil:
+		finding.Check = CheckIPOutsideSubnet
+		finding.Message = fmt.Sprintf("%s is not an ip address", address)
+	case err == nil && !subnet.Contains(ip):
+		finding.Check = CheckIPOutsideSubnet
+		finding.Message = fmt.Sprintf("ip %s is outside subnet %s of network %s", address, network.Subnet, network.Name)
+	case inRanges(ip, network.ReservedIPRanges):
+		finding.Check = CheckIPInReservedRange
+		finding.Message = fmt.Sprintf("ip %s is in the reserved ranges %s of network %s", address, network.ReservedIPRanges, network.Name)
+	default:
+		return nil
+	}
+	return []Finding{finding}
+}
+
+// inRanges reports whether an ip is in a comma separated list of ips and
+// first-last ip ranges
+func inRanges(ip net.IP, ranges string) bool {
+	for _, r := range strings.Split(ranges, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(r), "-", 2)
+		first := net.ParseIP(strings.TrimSpace(bounds[0]))
+		last := first
+		if len(bounds) == 2 {
+			last = net.ParseIP(strings.TrimSpace(bounds[1]))
+		}
+		if first == nil || last == nil {
+			continue
+		}
+		if bytes.Compare(ip.To16(), first.To16()) >= 0 && bytes.Compare(ip.To16(), last.To16()) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func appendOwner(owners []string, owner string) []string {
+	i := sort.SearchStrings(owners, owner)
+	if i < len(owners) && owners[i] == owner {
+		return owners
+	}
+	owners = append(owners, "")
+	copy(owners[i+1:], owners[i:])
+	owners[i] = owner
+	return owners
+}
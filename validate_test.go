@@ -0,0 +1,96 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("Validate", func() {
+	var is *InstallationSettings
+
+	BeforeEach(func() {
+		is = NewInstallationSettingsJSON(fixture("installation_settings.json"))
+	})
+
+	It("finds nothing wrong with a consistent installation", func() {
+		Expect(is.Validate()).To(BeEmpty())
+	})
+
+	It("reports partitions in unknown availability zones", func() {
+		job, _ := is.Products[1].FindJob("router")
+		job.Partition[1].AvailabilityZoneReference = "deadbeef"
+		Expect(is.Validate()).To(ConsistOf(Finding{
+			Severity: SeverityError,
+			Check:    CheckUnknownAvailabilityZone,
+			Product:  "cf",
+			Job:      "router",
+			Message:  "partition router-partition-0c1b8e8e9268e6f253d6 references unknown availability zone deadbeef",
+		}))
+	})
+
+	It("reports ips outside the subnet and in reserved ranges", func() {
+		is.Products[1].IPS["ccdb-7446a09800dd026253c9-partition-e6f2e103df59e642f38b"] = []string{"10.0.0.5"}
+		is.Products[1].IPS["uaadb-15148a14fe74db679d61-partition-e6f2e103df59e642f38b"] = []string{"192.168.201.7"}
+		findings := is.Validate()
+		Expect(findings).To(HaveLen(2))
+		Expect(findings[0].Check).To(Equal(CheckIPOutsideSubnet))
+		Expect(findings[0].Job).To(Equal("ccdb"))
+		Expect(findings[0].Message).To(Equal("ip 10.0.0.5 is outside subnet 192.168.200.0/23 of network PCF Deployment Network"))
+		Expect(findings[1].Check).To(Equal(CheckIPInReservedRange))
+		Expect(findings[1].Job).To(Equal("uaadb"))
+	})
+
+	It("reports static ips outside the subnet and in reserved ranges", func() {
+		Expect(is.SetProperty("cf", "ha_proxy", "static_ips", "10.0.0.20, 192.168.200.5")).To(Succeed())
+		findings := is.Validate()
+		Expect(findings).To(ConsistOf(
+			Finding{
+				Severity: SeverityError,
+				Check:    CheckIPOutsideSubnet,
+				Product:  "cf",
+				Job:      "ha_proxy",
+				Message:  "ip 10.0.0.20 is outside subnet 192.168.200.0/23 of network PCF Deployment Network",
+			},
+			Finding{
+				Severity: SeverityError,
+				Check:    CheckIPInReservedRange,
+				Product:  "cf",
+				Job:      "ha_proxy",
+				Message:  "ip 192.168.200.5 is in the reserved ranges 192.168.200.1-192.168.200.10,192.168.201.1-192.168.201.254 of network PCF Deployment Network",
+			},
+		))
+	})
+
+	It("reports ips assigned to several jobs", func() {
+		is.Products[1].IPS["ccdb-7446a09800dd026253c9-partition-e6f2e103df59e642f38b"] = []string{"192.168.200.11"}
+		findings := is.Validate()
+		Expect(findings).To(ConsistOf(Finding{
+			Severity: SeverityError,
+			Check:    CheckDuplicateIP,
+			Product:  "cf",
+			Job:      "ccdb",
+			Message:  "ip 192.168.200.11 is assigned to cf/ccdb, p-bosh/director",
+		}))
+	})
+
+	It("warns about quorum jobs with an even number of instances", func() {
+		Expect(is.SetInstanceCount("cf", "consul_server", 2)).To(Succeed())
+		findings := is.Validate()
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Severity).To(Equal(SeverityWarning))
+		Expect(findings[0].Check).To(Equal(CheckEvenQuorum))
+		Expect(findings[0].Job).To(Equal("consul_server"))
+	})
+
+	It("reports critical jobs without instances", func() {
+		Expect(is.SetInstanceCount("cf", "router", 0)).To(Succeed())
+		Expect(is.Validate()).To(ConsistOf(Finding{
+			Severity: SeverityError,
+			Check:    CheckCriticalJobStopped,
+			Product:  "cf",
+			Job:      "router",
+			Message:  "critical job has no instances",
+		}))
+	})
+})
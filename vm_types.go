@@ -0,0 +1,81 @@
+package opsmanclient
+
+import "fmt"
+
+// ListVMTypes returns the vm types available to jobs
+func (c *OpsManAPI) ListVMTypes() ([]VMType, error) {
+	var resp struct {
+		VMTypes []VMType `json:"vm_types"`
+	}
+	if err := c.apiRequest("GET", "v0/vm_types", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.VMTypes, nil
+}
+
+// ListDiskTypes returns the persistent disk types available to jobs
+func (c *OpsManAPI) ListDiskTypes() ([]DiskType, error) {
+	var resp struct {
+		DiskTypes []DiskType `json:"disk_types"`
+	}
+	if err := c.apiRequest("GET", "v0/disk_types", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.DiskTypes, nil
+}
+
+// ReplaceVMTypes replaces all the vm types with the given custom vm types
+func (c *OpsManAPI) ReplaceVMTypes(vmTypes []VMType) error {
+	custom := make([]VMType, len(vmTypes))
+	for i, vmType := range vmTypes {
+		vmType.Builtin = false
+		custom[i] = vmType
+	}
+	return c.apiRequest("PUT", "v0/vm_types", map[string][]VMType{"vm_types": custom}, nil)
+}
+
+// CreateVMType adds a custom vm type, or replaces the vm type with the same
+// name, keeping all the other vm types currently available
+func (c *OpsManAPI) CreateVMType(vmType VMType) error {
+	vmTypes, err := c.ListVMTypes()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range vmTypes {
+		if vmTypes[i].Name == vmType.Name {
+			vmTypes[i] = vmType
+			replaced = true
+		}
+	}
+	if !replaced {
+		vmTypes = append(vmTypes, vmType)
+	}
+	return c.ReplaceVMTypes(vmTypes)
+}
+
+// DeleteCustomVMTypes restores the default vm types of the IaaS
+func (c *OpsManAPI) DeleteCustomVMTypes() error {
+	return c.apiRequest("DELETE", "v0/vm_types", nil, nil)
+}
+
+// FindVMType returns the vm type with the given name
+func FindVMType(vmTypes []VMType, name string) (*VMType, error) {
+	for i := range vmTypes {
+		if vmTypes[i].Name == name {
+			return &vmTypes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("vm type %s is not available", name)
+}
+
+// FindDiskType returns the disk type with the given name
+func FindDiskType(diskTypes []DiskType, name string) (*DiskType, error) {
+	for i := range diskTypes {
+		if diskTypes[i].Name == name {
+			return &diskTypes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("disk type %s is not available", name)
+}
@@ -0,0 +1,58 @@
+package opsmanclient_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotalservices/opsmanclient"
+)
+
+var _ = Describe("VM types", func() {
+	JustBeforeEach(func() {
+		opsman.InitializeVMTypesTest([]opsmanclient.VMType{
+			{Name: "micro", RAM: 1024, CPU: 1, EphemeralDisk: 8192, Builtin: true},
+			{Name: "large", RAM: 8192, CPU: 2, EphemeralDisk: 16384, Builtin: true},
+		}, []opsmanclient.DiskType{
+			{Name: "1024", SizeMB: 1024, Builtin: true},
+		})
+	})
+
+	Describe("listing", func() {
+		It("returns the vm types", func() {
+			vmTypes, err := c.ListVMTypes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vmTypes).To(HaveLen(2))
+
+			large, err := opsmanclient.FindVMType(vmTypes, "large")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(large.RAM).To(Equal(8192))
+
+			_, err = opsmanclient.FindVMType(vmTypes, "xlarge")
+			Expect(err).To(MatchError("vm type xlarge is not available"))
+		})
+
+		It("returns the disk types", func() {
+			diskTypes, err := c.ListDiskTypes()
+			Expect(err).NotTo(HaveOccurred())
+
+			disk, err := opsmanclient.FindDiskType(diskTypes, "1024")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(disk.SizeMB).To(Equal(1024))
+		})
+	})
+
+	Describe("creating a custom vm type", func() {
+		It("keeps the existing vm types", func() {
+			err := c.CreateVMType(opsmanclient.VMType{Name: "diego_cell", RAM: 32768, CPU: 4, EphemeralDisk: 65536})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsman.VMTypes).To(HaveLen(3))
+			Expect(opsman.VMTypes[2].Name).To(Equal("diego_cell"))
+		})
+
+		It("replaces a vm type with the same name", func() {
+			err := c.CreateVMType(opsmanclient.VMType{Name: "large", RAM: 16384, CPU: 4, EphemeralDisk: 32768})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opsman.VMTypes).To(HaveLen(2))
+			Expect(opsman.VMTypes[1].RAM).To(Equal(16384))
+		})
+	})
+})